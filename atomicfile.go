@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// tempFilePrefix starts the name of the files being written, readers of the
+// zone directory never see them under their final name until complete
+const tempFilePrefix = ".tmp-"
+
+// writeFileAtomic replaces name with content through a temporary file in the
+// same directory, so readers see either the old or the new file and never a
+// partial one. Nothing is written if the file already has that content, the
+// returned bool tells if the file changed
+func writeFileAtomic(name string, content []byte, syncDir bool) (bool, error) {
+	if current, err := ioutil.ReadFile(name); err == nil && bytes.Equal(current, content) {
+		return false, nil
+	}
+
+	dir, base := filepath.Split(name)
+	if dir == "" {
+		dir = "."
+	}
+
+	file, err := ioutil.TempFile(dir, tempFilePrefix+base+"-")
+	if err != nil {
+		return false, err
+	}
+	tempName := file.Name()
+
+	// the temporary file is only left behind if the rename didn't happen
+	renamed := false
+	defer func() {
+		if !renamed {
+			os.Remove(tempName)
+		}
+	}()
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return false, err
+	}
+
+	// content must be on disk before the rename makes it visible
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return false, err
+	}
+
+	if err := file.Close(); err != nil {
+		return false, err
+	}
+
+	// ioutil.TempFile creates the file 0600, coredns may run as another user
+	if err := os.Chmod(tempName, 0644); err != nil {
+		return false, err
+	}
+
+	if err := os.Rename(tempName, name); err != nil {
+		return false, err
+	}
+	renamed = true
+
+	// the rename itself only survives a crash once the directory is synced
+	if syncDir {
+		if err := fsyncDir(dir); err != nil {
+			return true, err
+		}
+	}
+
+	return true, nil
+}
+
+// fsyncDir flushes the entries of a directory to disk
+func fsyncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return file.Sync()
+}
+
+// isTempFile reports if name was left by writeFileAtomic
+func isTempFile(name string) bool {
+	return strings.HasPrefix(filepath.Base(name), tempFilePrefix)
+}
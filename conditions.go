@@ -0,0 +1,62 @@
+package main
+
+import (
+	v1 "github.com/estaleiro/dns-controller/pkg/apis/dns/v1"
+	corev1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition reasons
+const (
+	reasonRendered     = "Rendered"
+	reasonInvalidSpec  = "InvalidSpec"
+	reasonRenderFailed = "RenderFailed"
+	reasonSyncFailed   = "SyncFailed"
+	reasonZoneConflict = "ZoneConflict"
+	reasonZoneNotFound = "ZoneNotFound"
+	reasonNameConflict = "NameConflict"
+	reasonNoConflict   = "NoConflict"
+	reasonValid        = "Valid"
+
+	reasonNamespaceNotAllowed = "NamespaceNotAllowed"
+)
+
+// setCondition sets a condition, the transition time only moves when the
+// status changes
+func setCondition(conditions *[]v1.Condition, conditionType v1.ConditionType, status bool, reason, message string) {
+	condition := v1.Condition{
+		Type:               conditionType,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: meta.Now(),
+		Reason:             reason,
+		Message:            message,
+	}
+	if status {
+		condition.Status = corev1.ConditionTrue
+	}
+
+	for i := range *conditions {
+		current := &(*conditions)[i]
+		if current.Type != conditionType {
+			continue
+		}
+
+		if current.Status == condition.Status {
+			condition.LastTransitionTime = current.LastTransitionTime
+		}
+		*current = condition
+		return
+	}
+
+	*conditions = append(*conditions, condition)
+}
+
+// findCondition returns the condition of a type, nil when it isn't set
+func findCondition(conditions []v1.Condition, conditionType v1.ConditionType) *v1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
@@ -2,34 +2,58 @@ package main
 
 import (
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"k8s.io/apimachinery/pkg/labels"
 
 	v1 "github.com/estaleiro/dns-controller/pkg/apis/dns/v1"
+	"github.com/estaleiro/dns-controller/pkg/client/clientset/versioned"
+	informers "github.com/estaleiro/dns-controller/pkg/client/informers/externalversions"
 	listers "github.com/estaleiro/dns-controller/pkg/client/listers/dns/v1"
 	log "github.com/sirupsen/logrus"
-	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/rand"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 )
 
 // Controller defines all we need to run controller
 type Controller struct {
-	logger               *log.Entry
-	clientset            kubernetes.Interface
-	queue                workqueue.RateLimitingInterface
-	zoneInformer         cache.SharedIndexInformer
-	zoneLister           listers.DNSZoneLister
-	recordInformer       cache.SharedIndexInformer
-	recordLister         listers.DNSRecordLister
-	zoneHandler          Handler
-	recordHandler        Handler
-	zoneDeletedIndexer   cache.Indexer
-	recordDeletedIndexer cache.Indexer
+	logger          *log.Entry
+	clientset       kubernetes.Interface
+	zoneClientset   versioned.Interface
+	recordClientset versioned.Interface
+	queue           workqueue.RateLimitingInterface
+	zoneLister      listers.DNSZoneLister
+	recordLister    listers.DNSRecordLister
+	zoneHandler     *ZoneHandler
+	recordHandler   *RecordHandler
+	recorder        record.EventRecorder
+	maxRetries      int
+	controllerClass string
+	finalizer       string
+	namespaces      []string
+	selector        labels.Selector
+	reconcilePeriod time.Duration
+	workers         int
+	debouncer       *debouncer
+
+	// informers of the watched namespaces
+	informerFactories []informers.SharedInformerFactory
+	informersSynced   []cache.InformerSynced
+
+	// time a worker last finished an item, unset until workers run
+	progress atomic.Value
 }
 
 // Run starts controller
@@ -40,199 +64,517 @@ func (c *Controller) Run(stopCh <-chan struct{}) {
 
 	c.logger.Info("Controller.Run: initiating")
 
-	go c.zoneInformer.Run(stopCh)
-	go c.recordInformer.Run(stopCh)
-
 	// do the initial synchronization (one time) to populate resources
 	if !cache.WaitForCacheSync(stopCh, c.HasSynced) {
-		utilruntime.HandleError(fmt.Errorf("Error syncing cache"))
+		c.logger.Error("Controller.Run: error syncing cache")
 		return
 	}
 
 	c.logger.Info("Controller.Run: cache sync complete")
 
-	// run the runWorker method every second with a stop channel
-	wait.Until(c.runWorker, time.Second, stopCh)
+	if err := c.zoneHandler.Init(c.logger); err != nil {
+		c.logger.Errorf("Controller.Run: error initializing zone handler: %v", err)
+		return
+	}
+
+	var running sync.WaitGroup
+
+	// reconcile right away and then periodically
+	running.Add(1)
+	go func() {
+		defer running.Done()
+		wait.Until(c.reconcile, c.reconcilePeriod, stopCh)
+	}()
+
+	c.markProgress()
+
+	// each worker runs the runWorker method every second with a stop channel
+	for i := 0; i < c.workers; i++ {
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			wait.Until(c.runWorker, time.Second, stopCh)
+		}()
+	}
+
+	<-stopCh
+
+	// nothing is queued anymore, the workers finish what is left and return
+	c.logger.Info("Controller.Run: shutting down, draining the queue")
+	c.debouncer.Flush()
+	c.queue.ShutDown()
+	running.Wait()
+
+	c.logger.Info("Controller.Run: shutdown complete")
+}
+
+// RunInformers fills the caches until stopCh is closed, standby replicas
+// run them too so they can take over without a full list
+func (c *Controller) RunInformers(stopCh <-chan struct{}) {
+	for _, factory := range c.informerFactories {
+		factory.Start(stopCh)
+	}
 }
 
 // HasSynced check if informer had finished to sync
 func (c *Controller) HasSynced() bool {
-	return c.zoneInformer.HasSynced() && c.recordInformer.HasSynced()
+	for _, synced := range c.informersSynced {
+		if !synced() {
+			return false
+		}
+	}
+	return true
+}
+
+// reconcile removes orphaned files and queues every zone again so files
+// drifting from the resources are rendered back
+func (c *Controller) reconcile() {
+	// the reconcile isn't a sync of a zone, it gets its own ID
+	logger := c.logger.WithField("reconcile", rand.String(8))
+	logger.Info("Controller.reconcile: starting")
+
+	if err := c.zoneHandler.Reconcile(logger); err != nil {
+		logger.Errorf("Controller.reconcile: error reconciling zones: %v", err)
+	}
+
+	zones, err := c.zoneLister.List(labels.Everything())
+	if err != nil {
+		logger.Errorf("Controller.reconcile: error listing zones: %v", err)
+		return
+	}
+
+	records, err := c.recordLister.List(labels.Everything())
+	if err != nil {
+		logger.Errorf("Controller.reconcile: error listing records: %v", err)
+		return
+	}
+
+	// the queue holds each zone once however many objects refer to it
+	for _, zone := range zones {
+		c.queue.Add(zoneName(zone))
+	}
+	for _, record := range records {
+		c.queue.Add(recordZoneName(record))
+	}
 }
 
 // runWorker executes the loop to process new items added to the queue
 func (c *Controller) runWorker() {
-	log.Info("Controller.runWorker: starting")
+	c.logger.Debug("Controller.runWorker: starting")
 
 	for c.processNextItem() {
-		log.Info("Controller.runWorker: processing next item")
+		c.logger.Debug("Controller.runWorker: processing next item")
 	}
 
-	log.Info("Controller.runWorker: completed")
+	c.logger.Debug("Controller.runWorker: completed")
 }
 
-// processNextItem retrieves each queued item and takes the
-// necessary handler action based off of if the item was
-// created or deleted
+// processNextItem retrieves each queued zone and syncs the zone and record
+// objects referring to it. The queue never hands the same key to two
+// workers at once, so a zone is only synced by one worker at a time
 func (c *Controller) processNextItem() bool {
-	log.Info("Controller.processNextItem: start")
-
 	key, quit := c.queue.Get()
 
 	if quit {
 		return false
 	}
 
-	err := func(obj interface{}) error {
+	func(obj interface{}) {
 		defer c.queue.Done(obj)
+		defer c.markProgress()
+
+		// objects leaving the instance only need their finalizer removed
+		if key, ok := obj.(releaseKey); ok {
+			c.processRelease(key)
+			return
+		}
 
-		var dnsResource DNSResource
+		var name string
 		var ok bool
 
-		if dnsResource, ok = obj.(DNSResource); !ok {
+		if name, ok = obj.(string); !ok {
 			c.queue.Forget(obj)
 			c.logger.Errorf("Controller.processNextItem: expected string in workqueue but got %#v", obj)
-			return nil
+			return
 		}
 
-		if dnsResource.Type == Zone {
-			if err := c.syncZoneHandler(dnsResource); err != nil {
-				c.logger.Errorf("Controller.processNextItem: error syncing zone '%s': %s", dnsResource.Key, err.Error())
-				return err
-			}
-		} else {
-			if err := c.syncRecordHandler(dnsResource); err != nil {
-				c.logger.Errorf("Controller.processNextItem: error syncing record '%s': %s", dnsResource.Key, err.Error())
-				return err
-			}
+		// every line logged by this sync can be found by its reconcile ID
+		logger := c.logger.WithFields(log.Fields{"zone": name, "reconcile": rand.String(8)})
+		logger.Debug("Controller.processNextItem: syncing zone")
+
+		err := c.syncZone(logger, name)
+
+		if err == nil {
+			c.queue.Forget(name)
+			logger.Debug("Controller.processNextItem: successfully synced zone")
+			return
 		}
-		c.logger.Infof("Controller.processNextItem: successfully synced '%s'", dnsResource.Key)
 
-		return nil
-	}(key)
+		if c.queue.NumRequeues(name) < c.maxRetries {
+			c.queue.AddRateLimited(name)
+			logger.Errorf("Controller.processNextItem: error syncing zone, retrying: %v", err)
+			return
+		}
 
-	if err != nil {
-		utilruntime.HandleError(err)
-		return true
-	}
+		c.queue.Forget(name)
+		c.syncFailed(logger, name, err)
+		logger.Errorf("Controller.processNextItem: error syncing zone, no more retries: %v", err)
+	}(key)
 
 	// keep the worker loop running by returning true
 	return true
 }
 
-func (c *Controller) syncZoneHandler(dnsResource DNSResource) error {
-	key := dnsResource.Key
+// processRelease removes the finalizer of an object the instance no longer
+// watches, failures are retried like zone syncs
+func (c *Controller) processRelease(key releaseKey) {
+	logger := c.logger.WithFields(log.Fields{
+		"resource":  key.resource.String(),
+		"namespace": key.namespace,
+		"name":      key.name,
+	})
+
+	err := c.releaseFinalizer(key)
+	if err == nil {
+		c.queue.Forget(key)
+		return
+	}
+
+	if c.queue.NumRequeues(key) < c.maxRetries {
+		c.queue.AddRateLimited(key)
+		logger.Errorf("Controller.processRelease: error removing finalizer, retrying: %v", err)
+		return
+	}
 
-	zoneItem, zoneExists, err := c.zoneInformer.GetIndexer().GetByKey(key)
-	if err != nil {
-		if c.queue.NumRequeues(dnsResource) < 5 {
-			c.queue.AddRateLimited(dnsResource)
-			return fmt.Errorf("failed processing item with key %s with error %v, retrying", key, err)
+	c.queue.Forget(key)
+	logger.Errorf("Controller.processRelease: error removing finalizer, no more retries: %v", err)
+}
+
+// releaseFinalizer removes the finalizer of an object that left the zones
+// and records the instance serves. The object is read from the API, the
+// informers no longer hold it
+func (c *Controller) releaseFinalizer(key releaseKey) error {
+	switch key.resource {
+	case Zone:
+		zone, err := c.zoneClientset.EstaleiroV1().DNSZones(key.namespace).Get(key.name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
 		}
-		c.queue.Forget(dnsResource)
-		return fmt.Errorf("failed processing item with key %s with error %v, no more retries", key, err)
+		if c.inScope(zone, zone.Spec.ControllerClass) || !hasFinalizer(zone, c.finalizer) {
+			return nil
+		}
+
+		zoneCopy := zone.DeepCopy()
+		removeFinalizer(zoneCopy, c.finalizer)
+		if _, err := c.zoneClientset.EstaleiroV1().DNSZones(key.namespace).Update(zoneCopy); err != nil {
+			return err
+		}
+		objectLogger(c.logger, Zone, zone).Info("Controller.releaseFinalizer: zone left the instance, finalizer removed")
+	case Record:
+		record, err := c.recordClientset.EstaleiroV1().DNSRecords(key.namespace).Get(key.name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if c.inScope(record, record.Spec.ControllerClass) || !hasFinalizer(record, c.finalizer) {
+			return nil
+		}
+
+		recordCopy := record.DeepCopy()
+		removeFinalizer(recordCopy, c.finalizer)
+		if _, err := c.recordClientset.EstaleiroV1().DNSRecords(key.namespace).Update(recordCopy); err != nil {
+			return err
+		}
+		objectLogger(c.logger, Record, record).Info("Controller.releaseFinalizer: record left the instance, finalizer removed")
 	}
 
-	if !zoneExists {
-		zoneItemDeleted, zoneExistsDeleted, err := c.zoneDeletedIndexer.GetByKey(key)
+	return nil
+}
 
-		if err != nil || !zoneExistsDeleted {
-			c.zoneDeletedIndexer.Delete(key)
-			c.queue.Forget(dnsResource)
-			return fmt.Errorf("failed processing item with key %s with error %v, no more retries", key, err)
+// inScope reports if the instance watches and serves an object of class
+func (c *Controller) inScope(obj metav1.Object, class string) bool {
+	if class != c.controllerClass || !c.selector.Matches(labels.Set(obj.GetLabels())) {
+		return false
+	}
+
+	for _, namespace := range c.namespaces {
+		if namespace == metav1.NamespaceAll || namespace == obj.GetNamespace() {
+			return true
 		}
+	}
+	return false
+}
 
-		c.logger.Infof("Controller.syncZoneHandler: object deleted detected: %s", key)
-		c.zoneHandler.ObjectDeleted(zoneItemDeleted)
-		c.zoneDeletedIndexer.Delete(key)
-		c.queue.Forget(dnsResource)
-	} else {
-		zoneReceived := zoneItem.(*v1.DNSZone)
-		zoneToCreate := zoneReceived
+// syncZone syncs every zone and record object of a zone. The zone is
+// rendered once, records take their conditions from that zone file
+func (c *Controller) syncZone(logger *log.Entry, name string) error {
+	zones, err := c.zoneLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
 
-		// Get all the zones
-		zones, err := c.zoneLister.DNSZones(meta.NamespaceAll).List(labels.Everything())
+	records, err := c.recordLister.ByZone(name)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	declared, served := false, false
+
+	// the zone file rendered by this sync, nil when the zone isn't served
+	var file *zoneFile
+
+	for _, zone := range zones {
+		if zoneName(zone) != name {
+			continue
+		}
+		declared = true
+		served = served || zone.GetDeletionTimestamp() == nil
+
+		start := time.Now()
+		rendered, err := c.syncZoneHandler(objectLogger(logger, Zone, zone), zone, zones)
+		observeSync(Zone, start, err)
+		if rendered != nil {
+			file = rendered
+		}
 		if err != nil {
-			return fmt.Errorf("failed processing item with key %s with error %v, no more retries", key, err)
+			errs = append(errs, fmt.Errorf("zone %s/%s: %v", zone.GetNamespace(), zone.GetName(), err))
 		}
+	}
 
-		// check if exists multiples resource to same zone
-		// condition: same object name in another namespace
-		for _, zoneFound := range zones {
-			zoneFoundObjectName := zoneFound.GetObjectMeta().GetName()
-			zoneFoundNamespace := zoneFound.GetNamespace()
-			zoneReceivedObjectName := zoneReceived.GetObjectMeta().GetName()
-			zoneReceivedNamespace := zoneReceived.GetNamespace()
+	// a zone moved to another class leaves its files behind, the periodic
+	// reconcile deletes the other orphaned files
+	if !declared {
+		if err := c.zoneHandler.removeZone(logger, name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// records are checked once the zone is rendered, the retry or the update
+	// adding the finalizer of the zone brings it back to the queue
+	if len(errs) > 0 {
+		return utilerrors.NewAggregate(errs)
+	}
+	if active := activeZone(zones, name); active != nil && !hasFinalizer(active, c.finalizer) {
+		return nil
+	}
 
-			if zoneFoundObjectName == zoneReceivedObjectName && zoneFoundNamespace != zoneReceivedNamespace {
-				c.logger.Infof("Controller.syncZoneHandler: same object %v in namespaces %v and %v", zoneFoundObjectName, zoneFoundNamespace, zoneReceivedNamespace)
-				// if we found a older crd
-				if zoneToCreate.GetCreationTimestamp().After(zoneFound.GetCreationTimestamp().Time) {
-					// then we use zoneFound
-					zoneToCreate = zoneFound
-				}
-			}
+	for _, record := range records {
+		start := time.Now()
+		err := c.syncRecordHandler(objectLogger(logger, Record, record), record, file)
+		observeSync(Record, start, err)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("record %s/%s: %v", record.GetNamespace(), record.GetName(), err))
 		}
+	}
+
+	if len(errs) > 0 {
+		return utilerrors.NewAggregate(errs)
+	}
 
-		c.logger.Infof("Controller.syncZoneHandler: object created detected: %v", zoneToCreate.GetNamespace()+"/"+zoneToCreate.GetObjectMeta().GetName())
-		c.zoneHandler.ObjectCreated(zoneToCreate)
-		c.queue.Forget(dnsResource)
+	if served {
+		lastSyncTime.WithLabelValues(name).SetToCurrentTime()
+	} else {
+		lastSyncTime.DeleteLabelValues(name)
 	}
 
 	return nil
 }
 
-func (c *Controller) syncRecordHandler(dnsResource DNSResource) error {
-	key := dnsResource.Key
+// syncZoneHandler syncs a zone object, the zone file is returned when the
+// object is the one serving the zone
+func (c *Controller) syncZoneHandler(logger *log.Entry, zoneReceived *v1.DNSZone, zones []*v1.DNSZone) (*zoneFile, error) {
+	if zoneReceived.GetDeletionTimestamp() != nil {
+		return nil, c.finalizeZone(logger, zoneReceived)
+	}
 
-	namespace, _, err := cache.SplitMetaNamespaceKey(key)
-	if err != nil {
-		c.queue.AddRateLimited(dnsResource)
-		return fmt.Errorf("invalid resource key %s", key)
+	// the update brings the zone back to the queue
+	if !hasFinalizer(zoneReceived, c.finalizer) {
+		zoneCopy := zoneReceived.DeepCopy()
+		addFinalizer(zoneCopy, c.finalizer)
+		_, err := c.zoneClientset.EstaleiroV1().DNSZones(zoneCopy.GetNamespace()).Update(zoneCopy)
+		return nil, err
+	}
+
+	// check if exists multiples resource to same zone
+	// condition: same object name in another namespace, the older one is used
+	zoneToCreate := activeZone(zones, zoneName(zoneReceived))
+	if zoneToCreate == nil {
+		zoneToCreate = zoneReceived
+	}
+
+	zoneStatus := zoneReceived.DeepCopy()
+
+	// the zone is rendered from the object serving it only
+	if zoneToCreate != zoneReceived {
+		logger.Infof("Controller.syncZoneHandler: zone also declared by %s/%s", zoneToCreate.GetNamespace(), zoneToCreate.GetName())
+		message := fmt.Sprintf("zone %s is served by %s/%s", zoneName(zoneReceived), zoneToCreate.GetNamespace(), zoneToCreate.GetName())
+		setCondition(&zoneStatus.Status.Conditions, v1.ConditionConflict, true, reasonZoneConflict, message)
+		setCondition(&zoneStatus.Status.Conditions, v1.ConditionReady, false, reasonZoneConflict, message)
+		return nil, c.updateZoneStatus(zoneReceived, zoneStatus)
+	}
+	setCondition(&zoneStatus.Status.Conditions, v1.ConditionConflict, false, reasonNoConflict, "")
+
+	logger.Debug("Controller.syncZoneHandler: rendering zone")
+	file, handlerErr := c.zoneHandler.serveZone(logger, zoneStatus)
+
+	// the status tells what went wrong even when the handler failed
+	if err := c.updateZoneStatus(zoneReceived, zoneStatus); err != nil {
+		return file, err
+	}
+
+	return file, handlerErr
+}
+
+// syncRecordHandler syncs a record object, file is the zone file rendered by
+// the sync or nil when the zone isn't served
+func (c *Controller) syncRecordHandler(logger *log.Entry, recordReceived *v1.DNSRecord, file *zoneFile) error {
+	if recordReceived.GetDeletionTimestamp() != nil {
+		return c.finalizeRecord(logger, recordReceived)
+	}
+
+	// the update brings the record back to the queue
+	if !hasFinalizer(recordReceived, c.finalizer) {
+		recordCopy := recordReceived.DeepCopy()
+		addFinalizer(recordCopy, c.finalizer)
+		_, err := c.recordClientset.EstaleiroV1().DNSRecords(recordCopy.GetNamespace()).Update(recordCopy)
+		return err
+	}
+
+	recordToCreate := recordReceived.DeepCopy()
+
+	logger.Debug("Controller.syncRecordHandler: checking record")
+	c.recordHandler.setConditions(logger, recordToCreate, file)
+
+	return c.updateRecordStatus(recordReceived, recordToCreate)
+}
+
+// finalizeZone cleans up after a zone being deleted and releases it, a zone
+// declared in another namespace takes over within the same sync
+func (c *Controller) finalizeZone(logger *log.Entry, zone *v1.DNSZone) error {
+	if !c.ownsFinalizer(zone) {
+		return nil
+	}
+
+	logger.Info("Controller.finalizeZone: zone deleted")
+	if err := c.zoneHandler.ObjectDeleted(logger, zone); err != nil {
+		c.recorder.Event(zone, corev1.EventTypeWarning, reasonRenderFailed, err.Error())
+		return err
+	}
+	c.recorder.Event(zone, corev1.EventTypeNormal, reasonDeleted, fmt.Sprintf("zone %s no longer served from this object", zoneName(zone)))
+
+	zoneCopy := zone.DeepCopy()
+	c.releaseOwnFinalizers(zoneCopy)
+	_, err := c.zoneClientset.EstaleiroV1().DNSZones(zoneCopy.GetNamespace()).Update(zoneCopy)
+	return err
+}
+
+// finalizeRecord cleans up after a record being deleted and releases it
+func (c *Controller) finalizeRecord(logger *log.Entry, record *v1.DNSRecord) error {
+	if !c.ownsFinalizer(record) {
+		return nil
 	}
 
-	recordItem, recordExists, err := c.recordInformer.GetIndexer().GetByKey(key)
+	// the zone file rendered by the sync already leaves the record out
+	logger.Info("Controller.finalizeRecord: record deleted")
+	c.recorder.Event(record, corev1.EventTypeNormal, reasonDeleted, fmt.Sprintf("record removed from zone %s", recordZoneName(record)))
+
+	recordCopy := record.DeepCopy()
+	c.releaseOwnFinalizers(recordCopy)
+	_, err := c.recordClientset.EstaleiroV1().DNSRecords(recordCopy.GetNamespace()).Update(recordCopy)
+	return err
+}
+
+// ownsFinalizer reports if an object being deleted waits for the instance.
+// Objects of a class added before every class had its own finalizer carry
+// the shared one, which no other instance removes from them
+func (c *Controller) ownsFinalizer(obj metav1.Object) bool {
+	return hasFinalizer(obj, c.finalizer) || hasFinalizer(obj, sharedFinalizer)
+}
+
+// releaseOwnFinalizers removes the finalizers ownsFinalizer looks for
+func (c *Controller) releaseOwnFinalizers(obj metav1.Object) {
+	removeFinalizer(obj, c.finalizer)
+	removeFinalizer(obj, sharedFinalizer)
+}
+
+// syncFailed reports the objects of a zone that could not be synced after
+// all retries in their status and with an event
+func (c *Controller) syncFailed(logger *log.Entry, name string, syncErr error) {
+	message := fmt.Sprintf("sync failed after %d retries: %v", c.maxRetries, syncErr)
+	syncFailuresTotal.Inc()
+
+	zones, err := c.zoneLister.List(labels.Everything())
 	if err != nil {
-		if c.queue.NumRequeues(dnsResource) < 5 {
-			c.queue.AddRateLimited(dnsResource)
-			return fmt.Errorf("failed processing item with key %s with error %v, retrying", key, err)
-		} else {
-			c.queue.Forget(dnsResource)
-			return fmt.Errorf("failed processing item with key %s with error %v, no more retries", key, err)
-		}
+		logger.Errorf("Controller.syncFailed: error listing zones: %v", err)
+		return
 	}
 
-	if !recordExists {
-		recordItemDeleted, recordExistsDeleted, err := c.recordDeletedIndexer.GetByKey(key)
+	for _, zone := range zones {
+		if zoneName(zone) != name {
+			continue
+		}
+		c.recorder.Event(zone, corev1.EventTypeWarning, reasonSyncFailed, message)
 
-		if err != nil || !recordExistsDeleted {
-			c.recordDeletedIndexer.Delete(key)
-			c.queue.Forget(dnsResource)
-			return fmt.Errorf("failed processing item with key %s with error %v, no more retries", key, err)
+		zoneStatus := zone.DeepCopy()
+		setCondition(&zoneStatus.Status.Conditions, v1.ConditionReady, false, reasonSyncFailed, message)
+		if err := c.updateZoneStatus(zone, zoneStatus); err != nil {
+			objectLogger(logger, Zone, zone).Errorf("Controller.syncFailed: error updating status: %v", err)
 		}
+	}
 
-		c.logger.Infof("Controller.syncRecordHandler: object deleted detected: %s", key)
-		c.recordHandler.ObjectDeleted(recordItemDeleted)
-		c.recordDeletedIndexer.Delete(key)
-		c.queue.Forget(dnsResource)
-	} else {
-		recordToCreate := recordItem.(*v1.DNSRecord)
+	records, err := c.recordLister.ByZone(name)
+	if err != nil {
+		logger.Errorf("Controller.syncFailed: error listing records: %v", err)
+		return
+	}
 
-		records, err := c.recordLister.DNSRecords(namespace).List(labels.Everything())
-		if err != nil {
-			return fmt.Errorf("failed processing item with key %s with error %v, no more retries", key, err)
+	for _, record := range records {
+		c.recorder.Event(record, corev1.EventTypeWarning, reasonSyncFailed, message)
+
+		recordStatus := record.DeepCopy()
+		setCondition(&recordStatus.Status.Conditions, v1.ConditionReady, false, reasonSyncFailed, message)
+		if err := c.updateRecordStatus(record, recordStatus); err != nil {
+			objectLogger(logger, Record, record).Errorf("Controller.syncFailed: error updating status: %v", err)
 		}
+	}
+}
 
-		// check if exists multiple crd asking to create same Record
-		/*for _, recordFound := range records {
+// updateZoneStatus saves the status of a zone when it changed
+func (c *Controller) updateZoneStatus(old, zone *v1.DNSZone) error {
+	zone.Status.ObservedGeneration = zone.GetGeneration()
+	if equality.Semantic.DeepEqual(old.Status, zone.Status) {
+		return nil
+	}
 
-		}*/
-		c.logger.Infof("Controller.syncRecordHandler: records found %v", records)
+	if _, err := c.zoneClientset.EstaleiroV1().DNSZones(zone.GetNamespace()).UpdateStatus(zone); err != nil {
+		return err
+	}
+
+	// the events of a status that failed to save are recorded on retry
+	c.recordZoneEvents(old, zone)
+
+	return nil
+}
 
-		c.logger.Infof("Controller.syncRecordHandler: object created detected: %v", recordToCreate)
-		//c.recordHandler.ObjectCreated(recordToCreate)
-		c.queue.Forget(dnsResource)
+// updateRecordStatus saves the status of a record when it changed
+func (c *Controller) updateRecordStatus(old, record *v1.DNSRecord) error {
+	record.Status.ObservedGeneration = record.GetGeneration()
+	if equality.Semantic.DeepEqual(old.Status, record.Status) {
+		return nil
+	}
+
+	if _, err := c.recordClientset.EstaleiroV1().DNSRecords(record.GetNamespace()).UpdateStatus(record); err != nil {
+		return err
 	}
 
+	// the events of a status that failed to save are recorded on retry
+	c.recordRecordEvents(old, record)
+
 	return nil
 }
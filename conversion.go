@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	v1 "github.com/estaleiro/dns-controller/pkg/apis/dns/v1"
+	v2 "github.com/estaleiro/dns-controller/pkg/apis/dns/v2"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// conversionReview is the apiextensions.k8s.io ConversionReview, v1 and
+// v1beta1 share this layout and the apiextensions packages aren't vendored
+type conversionReview struct {
+	metav1.TypeMeta `json:",inline"`
+	Request         *conversionRequest  `json:"request,omitempty"`
+	Response        *conversionResponse `json:"response,omitempty"`
+}
+
+// conversionRequest holds the objects to convert
+type conversionRequest struct {
+	UID               types.UID              `json:"uid"`
+	DesiredAPIVersion string                 `json:"desiredAPIVersion"`
+	Objects           []runtime.RawExtension `json:"objects"`
+}
+
+// conversionResponse holds the converted objects in the order they were
+// sent
+type conversionResponse struct {
+	UID              types.UID              `json:"uid"`
+	ConvertedObjects []runtime.RawExtension `json:"convertedObjects"`
+	Result           metav1.Status          `json:"result"`
+}
+
+// conversionHandler answers ConversionReviews between the v1 and v2 DNSZone
+// and DNSRecord versions
+func conversionHandler(rw http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("error reading request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	review := conversionReview{}
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(rw, "expected a ConversionReview request", http.StatusBadRequest)
+		return
+	}
+
+	response := &conversionResponse{
+		UID:    review.Request.UID,
+		Result: metav1.Status{Status: metav1.StatusSuccess},
+	}
+	for _, object := range review.Request.Objects {
+		converted, err := convertObject(object.Raw, review.Request.DesiredAPIVersion)
+		if err != nil {
+			log.Errorf("conversion to %s failed: %v", review.Request.DesiredAPIVersion, err)
+			response.ConvertedObjects = nil
+			response.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+			break
+		}
+		response.ConvertedObjects = append(response.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+
+	review.Request = nil
+	review.Response = response
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(review); err != nil {
+		log.Errorf("error writing conversion response: %v", err)
+	}
+}
+
+// convertObject converts a serialized zone or record to apiVersion
+func convertObject(raw []byte, apiVersion string) ([]byte, error) {
+	typeMeta := metav1.TypeMeta{}
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, fmt.Errorf("error decoding object: %v", err)
+	}
+
+	if typeMeta.APIVersion == apiVersion {
+		return raw, nil
+	}
+
+	v1Version, v2Version := v1.SchemeGroupVersion.String(), v2.SchemeGroupVersion.String()
+	var converted interface{}
+
+	switch {
+	case typeMeta.Kind == "DNSZone" && typeMeta.APIVersion == v1Version && apiVersion == v2Version:
+		zone := &v1.DNSZone{}
+		if err := json.Unmarshal(raw, zone); err != nil {
+			return nil, fmt.Errorf("error decoding DNSZone: %v", err)
+		}
+		converted = v2.ConvertZoneFromV1(zone)
+	case typeMeta.Kind == "DNSZone" && typeMeta.APIVersion == v2Version && apiVersion == v1Version:
+		zone := &v2.DNSZone{}
+		if err := json.Unmarshal(raw, zone); err != nil {
+			return nil, fmt.Errorf("error decoding DNSZone: %v", err)
+		}
+		converted = v2.ConvertZoneToV1(zone)
+	case typeMeta.Kind == "DNSRecord" && typeMeta.APIVersion == v1Version && apiVersion == v2Version:
+		record := &v1.DNSRecord{}
+		if err := json.Unmarshal(raw, record); err != nil {
+			return nil, fmt.Errorf("error decoding DNSRecord: %v", err)
+		}
+		converted = v2.ConvertRecordFromV1(record)
+	case typeMeta.Kind == "DNSRecord" && typeMeta.APIVersion == v2Version && apiVersion == v1Version:
+		record := &v2.DNSRecord{}
+		if err := json.Unmarshal(raw, record); err != nil {
+			return nil, fmt.Errorf("error decoding DNSRecord: %v", err)
+		}
+		converted = v2.ConvertRecordToV1(record)
+	default:
+		return nil, fmt.Errorf("unsupported conversion of %s %s to %s", typeMeta.Kind, typeMeta.APIVersion, apiVersion)
+	}
+
+	return json.Marshal(converted)
+}
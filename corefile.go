@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"sort"
+	"text/template"
+)
+
+// corefile holds the data used to execute coredns.tmpl
+type corefile struct {
+	Port     int
+	Upstream string
+	Zones    []corefileZone
+}
+
+// corefileZone is the server block of a zone
+type corefileZone struct {
+	Name string
+	File string
+}
+
+// render executes coredns.tmpl
+func (c corefile) render() ([]byte, error) {
+	corednsTemplate, err := template.ParseFiles("coredns.tmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	// keep the output stable no matter the order zones are listed
+	sort.Slice(c.Zones, func(i, j int) bool {
+		return c.Zones[i].Name < c.Zones[j].Name
+	})
+
+	var buf bytes.Buffer
+	if err := corednsTemplate.Execute(&buf, c); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// debouncer holds keys back until no event came for them during the quiet
+// period, or for max since their first pending event, so a burst of events
+// on a zone produces a single sync
+type debouncer struct {
+	quiet time.Duration
+	max   time.Duration
+	add   func(key string)
+
+	lock    sync.Mutex
+	pending map[string]*pendingKey
+	flushed bool
+}
+
+// pendingKey is a key waiting for its burst of events to end
+type pendingKey struct {
+	first time.Time
+	timer *time.Timer
+}
+
+// newDebouncer returns a debouncer passing keys to add, a zero quiet period
+// passes them right away
+func newDebouncer(quiet, max time.Duration, add func(key string)) *debouncer {
+	return &debouncer{
+		quiet:   quiet,
+		max:     max,
+		add:     add,
+		pending: map[string]*pendingKey{},
+	}
+}
+
+// Add delays key until its events stop for the quiet period
+func (d *debouncer) Add(key string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.quiet <= 0 || d.flushed {
+		d.add(key)
+		return
+	}
+
+	now := time.Now()
+	p, found := d.pending[key]
+	if !found {
+		p = &pendingKey{first: now}
+		d.pending[key] = p
+	} else {
+		p.timer.Stop()
+	}
+
+	// the quiet period restarts with each event, up to max after the first
+	delay := d.quiet
+	if deadline := p.first.Add(d.max); now.Add(delay).After(deadline) {
+		delay = deadline.Sub(now)
+	}
+
+	p.timer = time.AfterFunc(delay, func() {
+		d.fire(key, p)
+	})
+}
+
+// fire passes a key on once its timer expires
+func (d *debouncer) fire(key string, p *pendingKey) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	// a stopped timer may still fire, the key is only passed once
+	if d.pending[key] != p {
+		return
+	}
+	delete(d.pending, key)
+
+	d.add(key)
+}
+
+// Flush passes every pending key right away, later keys aren't delayed
+func (d *debouncer) Flush() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	for key, p := range d.pending {
+		p.timer.Stop()
+		delete(d.pending, key)
+		d.add(key)
+	}
+	d.flushed = true
+}
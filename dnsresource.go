@@ -1,5 +1,12 @@
 package main
 
+import (
+	v1 "github.com/estaleiro/dns-controller/pkg/apis/dns/v1"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
 // DNSResourceType defines if resource is Zone or Record
 type DNSResourceType int
 
@@ -9,8 +16,77 @@ const (
 	Record DNSResourceType = 1
 )
 
-// DNSResource defines a resource
-type DNSResource struct {
-	Key  string
-	Type DNSResourceType
+// String returns the name of the type used in logs
+func (t DNSResourceType) String() string {
+	if t == Record {
+		return "record"
+	}
+	return "zone"
+}
+
+// zoneQueueKey returns the queue key of an object, the name of the zone it
+// belongs to. Zones and records share keys so changes affecting the same
+// zone are never synced concurrently
+func zoneQueueKey(obj interface{}) (string, DNSResourceType, bool) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	switch object := obj.(type) {
+	case *v1.DNSZone:
+		return zoneName(object), Zone, true
+	case *v1.DNSRecord:
+		return recordZoneName(object), Record, true
+	}
+
+	return "", Zone, false
+}
+
+// releaseKey is queued for a zone or record leaving the objects the
+// instance watches, a worker removes its finalizer
+type releaseKey struct {
+	resource  DNSResourceType
+	namespace string
+	name      string
+}
+
+// releaseQueueKey returns the release key of an object
+func releaseQueueKey(obj interface{}) (releaseKey, bool) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	switch object := obj.(type) {
+	case *v1.DNSZone:
+		return releaseKey{resource: Zone, namespace: object.GetNamespace(), name: object.GetName()}, true
+	case *v1.DNSRecord:
+		return releaseKey{resource: Record, namespace: object.GetNamespace(), name: object.GetName()}, true
+	}
+
+	return releaseKey{}, false
+}
+
+// objectClass returns the controller class of a zone or record
+func objectClass(obj interface{}) string {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+
+	switch object := obj.(type) {
+	case *v1.DNSZone:
+		return object.Spec.ControllerClass
+	case *v1.DNSRecord:
+		return object.Spec.ControllerClass
+	}
+
+	return ""
+}
+
+// objectLogger adds the fields identifying an object to logger
+func objectLogger(logger *log.Entry, resourceType DNSResourceType, obj metav1.Object) *log.Entry {
+	return logger.WithFields(log.Fields{
+		"resource":  resourceType.String(),
+		"namespace": obj.GetNamespace(),
+		"name":      obj.GetName(),
+	})
 }
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+
+	v1 "github.com/estaleiro/dns-controller/pkg/apis/dns/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Event reasons besides the condition reasons
+const (
+	reasonSerialBumped = "SerialBumped"
+	reasonDeleted      = "Deleted"
+)
+
+// recordConditionEvents records an event for each condition that changed
+// between two statuses of an object, so every sync doesn't repeat them
+func (c *Controller) recordConditionEvents(object runtime.Object, old, conditions []v1.Condition) {
+	changed := func(conditionType v1.ConditionType) *v1.Condition {
+		condition := findCondition(conditions, conditionType)
+		if condition == nil {
+			return nil
+		}
+
+		previous := findCondition(old, conditionType)
+		if previous != nil && previous.Status == condition.Status && previous.Reason == condition.Reason {
+			return nil
+		}
+
+		return condition
+	}
+
+	for _, conditionType := range []v1.ConditionType{v1.ConditionInvalid, v1.ConditionConflict} {
+		if condition := changed(conditionType); condition != nil && condition.Status == corev1.ConditionTrue {
+			c.recorder.Event(object, corev1.EventTypeWarning, condition.Reason, condition.Message)
+		}
+	}
+
+	condition := changed(v1.ConditionReady)
+	if condition == nil {
+		return
+	}
+
+	if condition.Status == corev1.ConditionTrue {
+		c.recorder.Event(object, corev1.EventTypeNormal, condition.Reason, "rendered in its zone file")
+		return
+	}
+
+	// syncFailed records its own event, even when the status can't be saved
+	if condition.Reason == reasonSyncFailed {
+		return
+	}
+
+	// invalid and conflicting objects were reported above
+	for _, conditionType := range []v1.ConditionType{v1.ConditionInvalid, v1.ConditionConflict} {
+		if other := findCondition(conditions, conditionType); other != nil && other.Status == corev1.ConditionTrue {
+			return
+		}
+	}
+	c.recorder.Event(object, corev1.EventTypeWarning, condition.Reason, condition.Message)
+}
+
+// recordZoneEvents records the events of a zone status update
+func (c *Controller) recordZoneEvents(old, zone *v1.DNSZone) {
+	c.recordConditionEvents(old, old.Status.Conditions, zone.Status.Conditions)
+
+	if zone.Status.Serial != 0 && zone.Status.Serial != old.Status.Serial {
+		c.recorder.Event(old, corev1.EventTypeNormal, reasonSerialBumped, fmt.Sprintf("serial bumped to %d", zone.Status.Serial))
+	}
+}
+
+// recordRecordEvents records the events of a record status update
+func (c *Controller) recordRecordEvents(old, record *v1.DNSRecord) {
+	c.recordConditionEvents(old, old.Status.Conditions, record.Status.Conditions)
+}
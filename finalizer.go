@@ -0,0 +1,48 @@
+package main
+
+import (
+	"github.com/estaleiro/dns-controller/pkg/apis/dns"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// sharedFinalizer is the finalizer of the instance without a class, it was
+// the finalizer of every class before each got its own
+var sharedFinalizer = dns.GroupName + "/dns-controller"
+
+// finalizerName returns the finalizer of the instance serving class, an
+// object moved to another class keeps the finalizer of its new instance
+// when the previous one removes its own
+func finalizerName(class string) string {
+	if class == "" {
+		return sharedFinalizer
+	}
+	return sharedFinalizer + "-" + class
+}
+
+// hasFinalizer reports if the object carries the finalizer
+func hasFinalizer(obj meta.Object, finalizer string) bool {
+	for _, name := range obj.GetFinalizers() {
+		if name == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// addFinalizer adds the finalizer to the object
+func addFinalizer(obj meta.Object, finalizer string) {
+	if !hasFinalizer(obj, finalizer) {
+		obj.SetFinalizers(append(obj.GetFinalizers(), finalizer))
+	}
+}
+
+// removeFinalizer removes the finalizer from the object
+func removeFinalizer(obj meta.Object, finalizer string) {
+	var finalizers []string
+	for _, name := range obj.GetFinalizers() {
+		if name != finalizer {
+			finalizers = append(finalizers, name)
+		}
+	}
+	obj.SetFinalizers(finalizers)
+}
@@ -1,130 +1,423 @@
 package main
 
 import (
+	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
-	"text/template"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	v1 "github.com/estaleiro/dns-controller/pkg/apis/dns/v1"
+	"github.com/estaleiro/dns-controller/pkg/client/clientset/versioned"
+	listers "github.com/estaleiro/dns-controller/pkg/client/listers/dns/v1"
 	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 )
 
-// Handler interface contains the methods that are required
-type Handler interface {
-	Init() error
-	ObjectCreated(obj interface{})
-	ObjectDeleted(obj interface{})
-	ObjectUpdated(objOld, objNew interface{})
-}
-
-// ZoneHandler is a implementation of Handler for Zone
+// ZoneHandler writes the master files of the zones and the Corefile. It
+// reports its outcome in the status of the zone it's given, the controller
+// passes a copy and saves that status afterwards. Errors are returned when
+// retrying may help, the controller then requeues the zone
 type ZoneHandler struct {
 	zoneDirectory string
+	corefile      string
+	dnsPort       int
+	upstream      string
+	zoneClientset versioned.Interface
+	zoneLister    listers.DNSZoneLister
+	recordLister  listers.DNSRecordLister
+	syncDir       bool
+
+	// zones are synced in parallel but share the Corefile
+	corefileLock sync.Mutex
+
+	// metadata saved with the last serial of each zone, by UID, the lister
+	// may not have caught up with it when the zone is rendered again
+	savedLock sync.Mutex
+	saved     map[types.UID]metav1.ObjectMeta
 }
 
 // Init handles any handler initialization
-func (t *ZoneHandler) Init() error {
-	log.Info("ZoneHandler.Init")
-	return nil
+func (t *ZoneHandler) Init(logger *log.Entry) error {
+	logger.Debug("ZoneHandler.Init")
+
+	// writes interrupted by a crash leave their temporary files behind
+	tempFiles, err := filepath.Glob(path.Join(t.zoneDirectory, tempFilePrefix+"*"))
+	if err != nil {
+		return fmt.Errorf("error listing temporary files: %v", err)
+	}
+	for _, file := range tempFiles {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error deleting temporary file: %v", err)
+		}
+	}
+
+	// make sure coredns has a Corefile to load even before any zone exists
+	return t.writeCorefile(logger)
 }
 
-// ObjectCreated is called when an object is created
-func (t *ZoneHandler) ObjectCreated(obj interface{}) {
-	zone := obj.(*v1.DNSZone)
+// Reconcile removes the files written for zones that no longer exist and
+// rewrites the Corefile, files the controller didn't write are never touched
+func (t *ZoneHandler) Reconcile(logger *log.Entry) error {
+	// files are listed first so a zone added meanwhile keeps its file
+	entries, err := ioutil.ReadDir(t.zoneDirectory)
+	if err != nil {
+		return fmt.Errorf("error listing zone directory: %v", err)
+	}
 
-	zoneName := zone.GetObjectMeta().GetName()
+	zones, err := t.zoneLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error listing zones: %v", err)
+	}
 
-	// namespace_object_zone
-	fileName := zone.GetNamespace() + "_" + zoneName
+	expected := map[string]bool{path.Clean(t.corefile): true}
+	for _, zone := range zones {
+		if activeZone(zones, zoneName(zone)) != zone {
+			continue
+		}
+		if file, err := t.masterFile(zoneName(zone)); err == nil {
+			expected[file] = true
+		}
+	}
 
-	zoneFile := path.Clean(t.zoneDirectory + "/" + fileName)
+	for _, entry := range entries {
+		file := path.Join(t.zoneDirectory, entry.Name())
+		// temporary files belong to writes in progress
+		if !entry.Mode().IsRegular() || isTempFile(file) || expected[file] || !ownedFile(file) {
+			continue
+		}
 
-	// check if zone file exists and exit
-	if _, err := os.Stat(zoneFile); !os.IsNotExist(err) {
-		log.Infof("zone file already exists: %v, recreating", zoneFile)
-		err = os.Remove(zoneFile)
-		if err != nil {
-			log.Errorf("error deleting zone file: %v", err)
-			return
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error deleting orphaned file: %v", err)
 		}
+		logger.WithField("file", file).Info("orphaned file deleted")
+	}
+
+	return t.writeCorefile(logger)
+}
+
+// serveZone writes the master file of a zone and the Corefile and sets the
+// status of the zone, the zone file is nil when the zone is invalid. Lines
+// are logged through the logger of the sync
+func (t *ZoneHandler) serveZone(logger *log.Entry, zone *v1.DNSZone) (*zoneFile, error) {
+	status := &zone.Status
+
+	// an invalid spec won't get better by retrying, the status tells why
+	if err := validateZone(zone); err != nil {
+		logger.Debugf("invalid zone: %v", err)
+		setCondition(&status.Conditions, v1.ConditionInvalid, true, reasonInvalidSpec, err.Error())
+		setCondition(&status.Conditions, v1.ConditionReady, false, reasonInvalidSpec, err.Error())
+		return nil, t.writeCorefile(logger)
 	}
+	setCondition(&status.Conditions, v1.ConditionInvalid, false, reasonValid, "")
 
-	// then create a new empty file
-	file, err := os.Create(zoneFile)
+	file, err := t.writeZoneFile(logger, zone)
+	if err == nil {
+		err = t.writeCorefile(logger)
+	}
 	if err != nil {
-		log.Errorf("error creating zone file: %v", err)
-		return
+		setCondition(&status.Conditions, v1.ConditionReady, false, reasonRenderFailed, err.Error())
+		return nil, fmt.Errorf("error writing zone %s: %v", zoneName(zone), err)
 	}
 
-	corednsTemplate, _ := template.ParseFiles("coredns.tmpl")
+	status.File = file.path
+	status.Serial = file.Serial
+	status.RecordCount = file.rendered
+	setCondition(&status.Conditions, v1.ConditionReady, true, reasonRendered, "")
+
+	logger.Debug("zone rendered")
+
+	return &file, nil
+}
+
+// ObjectDeleted is called when a zone is deleted
+func (t *ZoneHandler) ObjectDeleted(logger *log.Entry, zone *v1.DNSZone) error {
+	t.forgetSavedMeta(zone)
 
-	err = corednsTemplate.Execute(file, zoneName)
+	zoneName := zoneName(zone)
+
+	if err := t.writeCorefile(logger); err != nil {
+		return err
+	}
+
+	// the same zone may still be declared in another namespace
+	zones, err := t.zoneLister.List(labels.Everything())
 	if err != nil {
-		log.Errorf("error executing template: %v", err)
+		return fmt.Errorf("error listing zones: %v", err)
+	}
+
+	// the zone declared in another namespace rewrites the file when it is
+	// synced, it shares the queue key of this one. No file is ever written
+	// for an invalid zone name
+	if activeZone(zones, zoneName) == nil && isDomainName(zoneName) {
+		if err := t.removeZoneFile(zoneName); err != nil {
+			return err
+		}
 	}
 
-	defer file.Close()
+	logger.Debug("zone file removed")
+
+	return nil
+}
+
+// masterFile returns the path of the master file of a zone, names that
+// aren't domain names are refused so they can't point outside the zone
+// directory
+func (t *ZoneHandler) masterFile(zoneName string) (string, error) {
+	if !isDomainName(zoneName) {
+		return "", fmt.Errorf("invalid zone name %q", zoneName)
+	}
 
-	log.Infof("zone file %s created", zoneFile)
+	return path.Join(t.zoneDirectory, "db."+strings.TrimSuffix(zoneName, ".")), nil
 }
 
-// ObjectDeleted is called when an object is deleted
-func (t *ZoneHandler) ObjectDeleted(obj interface{}) {
-	zone := obj.(*v1.DNSZone)
+// writeZoneFile renders the master file of a zone with all its records
+func (t *ZoneHandler) writeZoneFile(logger *log.Entry, zone *v1.DNSZone) (zoneFile, error) {
+	start := time.Now()
+
+	file, err := t.renderZoneFile(logger, zone)
+	renderDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		renderErrorsTotal.Inc()
+	}
 
-	zoneName := zone.GetObjectMeta().GetName()
+	return file, err
+}
 
-	// namespace_object_zone
-	fileName := zone.GetNamespace() + "_" + zoneName
+// renderZoneFile renders and writes the master file, see writeZoneFile
+func (t *ZoneHandler) renderZoneFile(logger *log.Entry, zone *v1.DNSZone) (zoneFile, error) {
+	zoneName := zoneName(zone)
 
-	zoneFile := path.Clean(t.zoneDirectory + "/" + fileName)
+	if err := validateZone(zone); err != nil {
+		return zoneFile{}, fmt.Errorf("invalid zone: %v", err)
+	}
 
-	// check if zone file exists and remove it
-	if _, err := os.Stat(zoneFile); !os.IsNotExist(err) {
-		err = os.Remove(zoneFile)
-		if err != nil {
-			log.Errorf("error deleting zone file: %v", err)
+	records, err := t.recordLister.ByZone(zoneName)
+	if err != nil {
+		return zoneFile{}, fmt.Errorf("error listing records: %v", err)
+	}
+
+	data := newZoneFile(logger, zone, zoneRecords(records, zoneName))
+
+	hash, err := contentHash(data)
+	if err != nil {
+		return zoneFile{}, fmt.Errorf("error rendering zone: %v", err)
+	}
+
+	// the serial only moves when the zone content changes
+	t.restoreSavedMeta(zone)
+	serial, previousHash := zoneSerial(zone)
+	if serial == 0 || hash != previousHash {
+		serial = nextSerial(zone.Spec.SerialStrategy, serial, time.Now())
+
+		// persist it before it is served so a restart never reuses it
+		if err := t.persistSerial(zone, serial, hash); err != nil {
+			return zoneFile{}, fmt.Errorf("error saving serial: %v", err)
 		}
+		logger.WithField("serial", serial).Info("serial bumped")
+	}
+	data.Serial = serial
+
+	content, err := data.render()
+	if err != nil {
+		return zoneFile{}, fmt.Errorf("error rendering zone: %v", err)
+	}
+
+	masterFile, err := t.masterFile(zoneName)
+	if err != nil {
+		return zoneFile{}, err
+	}
+	data.path = masterFile
+
+	// files drifting from the resources are rewritten, the others left alone
+	written, err := writeFileAtomic(masterFile, content, t.syncDir)
+	if err != nil {
+		return zoneFile{}, fmt.Errorf("error writing master file: %v", err)
 	}
 
-	log.Infof("zone %s deleted", zoneName)
+	if written {
+		logger.WithField("file", masterFile).Info("master file written")
+	}
+
+	return data, nil
 }
 
-// ObjectUpdated is called when an object is updated
-func (t *ZoneHandler) ObjectUpdated(objOld, objNew interface{}) {
-	zone := objOld.(*v1.DNSZone)
+// persistSerial saves the serial and content hash of a zone as annotations
+func (t *ZoneHandler) persistSerial(zone *v1.DNSZone, serial uint32, hash string) error {
+	zoneCopy := zone.DeepCopy()
+
+	annotations := zoneCopy.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[serialAnnotation] = strconv.FormatUint(uint64(serial), 10)
+	annotations[contentHashAnnotation] = hash
+	zoneCopy.SetAnnotations(annotations)
 
-	zoneName := zone.GetObjectMeta().GetName()
+	updated, err := t.zoneClientset.EstaleiroV1().DNSZones(zoneCopy.GetNamespace()).Update(zoneCopy)
+	if err != nil {
+		return err
+	}
 
-	t.ObjectDeleted(objOld)
+	// keep the new resource version so the status can still be saved
+	zone.ObjectMeta = updated.ObjectMeta
 
-	t.ObjectCreated(objNew)
+	t.savedLock.Lock()
+	defer t.savedLock.Unlock()
+	if t.saved == nil {
+		t.saved = map[types.UID]metav1.ObjectMeta{}
+	}
+	t.saved[updated.GetUID()] = *updated.ObjectMeta.DeepCopy()
 
-	log.Infof("zone %s updated", zoneName)
+	return nil
 }
 
-// RecordHandler is a implementation of Handler for Record
-type RecordHandler struct {
-	zoneDirectory string
+// restoreSavedMeta replaces the metadata of a zone with the one saved with
+// its last serial when it is more recent
+func (t *ZoneHandler) restoreSavedMeta(zone *v1.DNSZone) {
+	t.savedLock.Lock()
+	defer t.savedLock.Unlock()
+
+	saved, found := t.saved[zone.GetUID()]
+	if !found {
+		return
+	}
+
+	savedSerial, _ := zoneSerial(&v1.DNSZone{ObjectMeta: saved})
+	if serial, _ := zoneSerial(zone); savedSerial > serial {
+		zone.ObjectMeta = *saved.DeepCopy()
+	}
 }
 
-// Init handles any handler initialization
-func (t *RecordHandler) Init() error {
-	log.Info("RecordHandler.Init")
-	return nil
+// forgetSavedMeta drops the metadata saved for a zone being deleted
+func (t *ZoneHandler) forgetSavedMeta(zone *v1.DNSZone) {
+	t.savedLock.Lock()
+	defer t.savedLock.Unlock()
+
+	delete(t.saved, zone.GetUID())
+}
+
+// removeZone stops serving a zone no object declares and removes its
+// master file, a name that isn't a domain name never had one
+func (t *ZoneHandler) removeZone(logger *log.Entry, zoneName string) error {
+	if err := t.writeCorefile(logger); err != nil {
+		return err
+	}
+
+	if !isDomainName(zoneName) {
+		return nil
+	}
+
+	return t.removeZoneFile(zoneName)
 }
 
-// ObjectCreated is called when an object is created
-func (t *RecordHandler) ObjectCreated(obj interface{}) {
-	log.Info("record added")
+// removeZoneFile removes the master file of a zone
+func (t *ZoneHandler) removeZoneFile(zoneName string) error {
+	masterFile, err := t.masterFile(zoneName)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(masterFile); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error deleting master file: %v", err)
+	}
+
+	if t.syncDir {
+		if err := fsyncDir(t.zoneDirectory); err != nil {
+			return fmt.Errorf("error syncing zone directory: %v", err)
+		}
+	}
+
+	return nil
 }
 
-// ObjectDeleted is called when an object is deleted
-func (t *RecordHandler) ObjectDeleted(obj interface{}) {
-	log.Infof("record deleted")
+// writeCorefile renders the Corefile with a server block per zone. The file
+// is only rewritten when its content changes so the reload plugin doesn't
+// restart the server for nothing
+func (t *ZoneHandler) writeCorefile(logger *log.Entry) error {
+	t.corefileLock.Lock()
+	defer t.corefileLock.Unlock()
+
+	zones, err := t.zoneLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error listing zones: %v", err)
+	}
+
+	data := corefile{Port: t.dnsPort, Upstream: t.upstream}
+	for _, zone := range zones {
+		zoneName := zoneName(zone)
+
+		// zones declared in several namespaces are served once
+		if activeZone(zones, zoneName) != zone {
+			continue
+		}
+
+		if err := validateZone(zone); err != nil {
+			continue
+		}
+
+		file, err := t.masterFile(zoneName)
+		if err != nil {
+			continue
+		}
+
+		data.Zones = append(data.Zones, corefileZone{Name: zoneName, File: file})
+	}
+
+	content, err := data.render()
+	if err != nil {
+		return fmt.Errorf("error rendering corefile: %v", err)
+	}
+
+	written, err := writeFileAtomic(t.corefile, content, t.syncDir)
+	if err != nil {
+		return fmt.Errorf("error writing corefile: %v", err)
+	}
+
+	if written {
+		logger.WithField("file", t.corefile).Info("corefile written")
+	}
+
+	return nil
 }
 
-// ObjectUpdated is called when an object is updated
-func (t *RecordHandler) ObjectUpdated(objOld, objNew interface{}) {
-	log.Info("record updated")
+// RecordHandler sets the conditions of records from the zone file their
+// zone was rendered to
+type RecordHandler struct{}
+
+// setConditions sets the conditions of a record from the zone file of its
+// zone, file is nil when the zone isn't served
+func (t *RecordHandler) setConditions(logger *log.Entry, record *v1.DNSRecord, file *zoneFile) {
+	conditions := &record.Status.Conditions
+
+	// the record is rendered again once its zone shows up
+	if file == nil {
+		message := fmt.Sprintf("zone %s is not served", recordZoneName(record))
+		setCondition(conditions, v1.ConditionReady, false, reasonZoneNotFound, message)
+		return
+	}
+
+	skipped, found := file.skipped[record.GetNamespace()+"/"+record.GetName()]
+	if !found {
+		setCondition(conditions, v1.ConditionInvalid, false, reasonValid, "")
+		setCondition(conditions, v1.ConditionConflict, false, reasonNoConflict, "")
+		setCondition(conditions, v1.ConditionReady, true, reasonRendered, "")
+		logger.Debug("record rendered")
+		return
+	}
+
+	if skipped.condition == v1.ConditionInvalid {
+		setCondition(conditions, v1.ConditionInvalid, true, skipped.reason, skipped.err.Error())
+		setCondition(conditions, v1.ConditionConflict, false, reasonNoConflict, "")
+	} else {
+		setCondition(conditions, v1.ConditionInvalid, false, reasonValid, "")
+		setCondition(conditions, v1.ConditionConflict, true, skipped.reason, skipped.err.Error())
+	}
+	setCondition(conditions, v1.ConditionReady, false, skipped.reason, skipped.err.Error())
 }
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+)
+
+// probe tells why the controller isn't healthy, nil when it is
+type probe func() error
+
+// serveHTTP serves /metrics, /healthz and /readyz on address until the
+// process exits
+func serveHTTP(address string, liveness, readiness probe) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/healthz", probeHandler(liveness))
+	mux.Handle("/readyz", probeHandler(readiness))
+
+	log.Infof("serving metrics and probes on %s", address)
+	if err := http.ListenAndServe(address, mux); err != nil {
+		log.Fatalf("error serving http: %v", err)
+	}
+}
+
+// probeHandler answers 200 when the probe passes and 503 with the reason
+// when it fails
+func probeHandler(check probe) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := check(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+}
+
+// markProgress notes that a worker finished an item
+func (c *Controller) markProgress() {
+	c.progress.Store(time.Now())
+}
+
+// liveness fails when items are waiting in the queue and no worker finished
+// one for longer than deadline. Standbys don't run workers and always pass
+func (c *Controller) liveness(deadline time.Duration) probe {
+	return func() error {
+		last, working := c.progress.Load().(time.Time)
+		if !working || c.queue.Len() == 0 {
+			return nil
+		}
+
+		if idle := time.Since(last); idle > deadline {
+			return fmt.Errorf("no progress for %v with %d zones queued", idle.Round(time.Second), c.queue.Len())
+		}
+
+		return nil
+	}
+}
+
+// readiness requires the caches to be synced and the zone directory to be
+// writable
+func (c *Controller) readiness(zoneDirectory string) probe {
+	return func() error {
+		if !c.HasSynced() {
+			return fmt.Errorf("caches not synced")
+		}
+
+		return checkWritable(zoneDirectory)
+	}
+}
+
+// checkWritable creates and removes a temporary file in dir
+func checkWritable(dir string) error {
+	file, err := ioutil.TempFile(dir, tempFilePrefix+"readyz-")
+	if err != nil {
+		return fmt.Errorf("zone directory not writable: %v", err)
+	}
+	file.Close()
+
+	return os.Remove(file.Name())
+}
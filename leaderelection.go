@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	coordinationv1beta1 "k8s.io/api/coordination/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	coordinationclient "k8s.io/client-go/kubernetes/typed/coordination/v1beta1"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/client-go/tools/record"
+)
+
+// leaderElectionOptions configures the Lease replicas compete for
+type leaderElectionOptions struct {
+	enabled       bool
+	name          string
+	namespace     string
+	identity      string
+	leaseDuration time.Duration
+	renewDeadline time.Duration
+	retryPeriod   time.Duration
+}
+
+// runLeaderElection blocks until stopCh is closed, calling run with a stop
+// channel of its own while this replica holds the lease. Losing the lease
+// exits the process so it restarts as a standby with a fresh queue. On
+// shutdown it waits for run to return and releases the lease, so a standby
+// takes over without waiting for it to expire
+func runLeaderElection(options leaderElectionOptions, client kubernetes.Interface, recorder record.EventRecorder, run func(stopCh <-chan struct{}), stopCh <-chan struct{}) {
+	lock := &leaseLock{
+		LeaseMeta: metav1.ObjectMeta{Name: options.name, Namespace: options.namespace},
+		Client:    client.CoordinationV1beta1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity:      options.identity,
+			EventRecorder: recorder,
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	// closed once run returns
+	finished := make(chan struct{})
+
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: options.leaseDuration,
+		RenewDeadline: options.renewDeadline,
+		RetryPeriod:   options.retryPeriod,
+		Name:          lock.Describe(),
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				defer close(finished)
+				log.Infof("leader election: %s started leading", options.identity)
+				run(ctx.Done())
+			},
+			OnStoppedLeading: func() {
+				// a shutdown also stops leading, only a lost lease is fatal
+				select {
+				case <-ctx.Done():
+					log.Infof("leader election: %s stopped leading", options.identity)
+				default:
+					log.Fatalf("leader election: %s lost the lease", options.identity)
+				}
+			},
+			OnNewLeader: func(identity string) {
+				log.Infof("leader election: %s is the leader", identity)
+			},
+		},
+	})
+	if err != nil {
+		log.Fatalf("leader election: %v", err)
+	}
+
+	elector.Run(ctx)
+
+	if !elector.IsLeader() {
+		return
+	}
+
+	<-finished
+
+	if err := lock.release(); err != nil {
+		log.Errorf("leader election: error releasing the lease: %v", err)
+		return
+	}
+	log.Infof("leader election: %s released the lease", options.identity)
+}
+
+// leaseLock is a resourcelock.Interface backed by a coordination Lease,
+// client-go only provides ConfigMap and Endpoints locks
+type leaseLock struct {
+	// LeaseMeta holds the name and namespace of the Lease
+	LeaseMeta  metav1.ObjectMeta
+	Client     coordinationclient.LeasesGetter
+	LockConfig resourcelock.ResourceLockConfig
+	lease      *coordinationv1beta1.Lease
+}
+
+// Get returns the election record from the Lease spec
+func (ll *leaseLock) Get() (*resourcelock.LeaderElectionRecord, error) {
+	var err error
+	ll.lease, err = ll.Client.Leases(ll.LeaseMeta.Namespace).Get(ll.LeaseMeta.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return leaseSpecToRecord(&ll.lease.Spec), nil
+}
+
+// Create attempts to create a Lease holding the election record
+func (ll *leaseLock) Create(ler resourcelock.LeaderElectionRecord) error {
+	var err error
+	ll.lease, err = ll.Client.Leases(ll.LeaseMeta.Namespace).Create(&coordinationv1beta1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ll.LeaseMeta.Name,
+			Namespace: ll.LeaseMeta.Namespace,
+		},
+		Spec: recordToLeaseSpec(&ler),
+	})
+	return err
+}
+
+// Update replaces the election record of an existing Lease
+func (ll *leaseLock) Update(ler resourcelock.LeaderElectionRecord) error {
+	if ll.lease == nil {
+		return errors.New("lease not initialized, call get or create first")
+	}
+	ll.lease.Spec = recordToLeaseSpec(&ler)
+
+	var err error
+	ll.lease, err = ll.Client.Leases(ll.LeaseMeta.Namespace).Update(ll.lease)
+	return err
+}
+
+// RecordEvent records an election event on the Lease
+func (ll *leaseLock) RecordEvent(s string) {
+	if ll.LockConfig.EventRecorder == nil || ll.lease == nil {
+		return
+	}
+	events := fmt.Sprintf("%v %v", ll.LockConfig.Identity, s)
+	ll.LockConfig.EventRecorder.Eventf(&coordinationv1beta1.Lease{ObjectMeta: ll.lease.ObjectMeta}, corev1.EventTypeNormal, "LeaderElection", events)
+}
+
+// release gives up the lease when this replica holds it, the holder is
+// cleared and the lease expires right away. Electors of client-go v10 still
+// wait a lease duration after they see the change, newer ones take over
+func (ll *leaseLock) release() error {
+	record, err := ll.Get()
+	if err != nil {
+		return err
+	}
+
+	if record.HolderIdentity != ll.Identity() {
+		return nil
+	}
+
+	now := metav1.Now()
+	return ll.Update(resourcelock.LeaderElectionRecord{
+		LeaseDurationSeconds: 1,
+		AcquireTime:          now,
+		RenewTime:            now,
+		LeaderTransitions:    record.LeaderTransitions,
+	})
+}
+
+// Describe returns the namespace and name of the Lease
+func (ll *leaseLock) Describe() string {
+	return fmt.Sprintf("%v/%v", ll.LeaseMeta.Namespace, ll.LeaseMeta.Name)
+}
+
+// Identity returns the identity of this replica
+func (ll *leaseLock) Identity() string {
+	return ll.LockConfig.Identity
+}
+
+// leaseSpecToRecord converts a Lease spec to an election record
+func leaseSpecToRecord(spec *coordinationv1beta1.LeaseSpec) *resourcelock.LeaderElectionRecord {
+	record := &resourcelock.LeaderElectionRecord{}
+
+	if spec.HolderIdentity != nil {
+		record.HolderIdentity = *spec.HolderIdentity
+	}
+	if spec.LeaseDurationSeconds != nil {
+		record.LeaseDurationSeconds = int(*spec.LeaseDurationSeconds)
+	}
+	if spec.LeaseTransitions != nil {
+		record.LeaderTransitions = int(*spec.LeaseTransitions)
+	}
+	if spec.AcquireTime != nil {
+		record.AcquireTime = metav1.Time{Time: spec.AcquireTime.Time}
+	}
+	if spec.RenewTime != nil {
+		record.RenewTime = metav1.Time{Time: spec.RenewTime.Time}
+	}
+
+	return record
+}
+
+// recordToLeaseSpec converts an election record to a Lease spec
+func recordToLeaseSpec(ler *resourcelock.LeaderElectionRecord) coordinationv1beta1.LeaseSpec {
+	leaseDurationSeconds := int32(ler.LeaseDurationSeconds)
+	leaseTransitions := int32(ler.LeaderTransitions)
+
+	return coordinationv1beta1.LeaseSpec{
+		HolderIdentity:       &ler.HolderIdentity,
+		LeaseDurationSeconds: &leaseDurationSeconds,
+		AcquireTime:          &metav1.MicroTime{Time: ler.AcquireTime.Time},
+		RenewTime:            &metav1.MicroTime{Time: ler.RenewTime.Time},
+		LeaseTransitions:     &leaseTransitions,
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	v1 "github.com/estaleiro/dns-controller/pkg/apis/dns/v1"
+	listers "github.com/estaleiro/dns-controller/pkg/client/listers/dns/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// namespacedZoneLister lists the zones of every watched namespace, each
+// namespace has its own informer
+type namespacedZoneLister map[string]listers.DNSZoneLister
+
+// List lists the zones of all watched namespaces
+func (l namespacedZoneLister) List(selector labels.Selector) ([]*v1.DNSZone, error) {
+	var zones []*v1.DNSZone
+	for _, lister := range l {
+		namespaceZones, err := lister.List(selector)
+		if err != nil {
+			return nil, err
+		}
+		zones = append(zones, namespaceZones...)
+	}
+	return zones, nil
+}
+
+// DNSZones returns the lister of a namespace, namespaces that aren't watched
+// have no zones
+func (l namespacedZoneLister) DNSZones(namespace string) listers.DNSZoneNamespaceLister {
+	if lister, ok := l[namespace]; ok {
+		return lister.DNSZones(namespace)
+	}
+	return listers.NewDNSZoneLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})).DNSZones(namespace)
+}
+
+// namespacedRecordLister lists the records of every watched namespace, each
+// namespace has its own informer
+type namespacedRecordLister map[string]listers.DNSRecordLister
+
+// List lists the records of all watched namespaces
+func (l namespacedRecordLister) List(selector labels.Selector) ([]*v1.DNSRecord, error) {
+	var records []*v1.DNSRecord
+	for _, lister := range l {
+		namespaceRecords, err := lister.List(selector)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, namespaceRecords...)
+	}
+	return records, nil
+}
+
+// ByZone lists the records of a zone in all watched namespaces
+func (l namespacedRecordLister) ByZone(zoneName string) ([]*v1.DNSRecord, error) {
+	var records []*v1.DNSRecord
+	for _, lister := range l {
+		namespaceRecords, err := lister.ByZone(zoneName)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, namespaceRecords...)
+	}
+	return records, nil
+}
+
+// DNSRecords returns the lister of a namespace, namespaces that aren't
+// watched have no records
+func (l namespacedRecordLister) DNSRecords(namespace string) listers.DNSRecordNamespaceLister {
+	if lister, ok := l[namespace]; ok {
+		return lister.DNSRecords(namespace)
+	}
+	return listers.NewDNSRecordLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})).DNSRecords(namespace)
+}
+
+// classZoneLister only lists the zones of a controller class
+type classZoneLister struct {
+	lister listers.DNSZoneLister
+	class  string
+}
+
+// List lists the zones of the class
+func (l classZoneLister) List(selector labels.Selector) ([]*v1.DNSZone, error) {
+	zones, err := l.lister.List(selector)
+	if err != nil {
+		return nil, err
+	}
+	return classZones(zones, l.class), nil
+}
+
+// DNSZones returns a lister of the zones of the class in a namespace
+func (l classZoneLister) DNSZones(namespace string) listers.DNSZoneNamespaceLister {
+	return classZoneNamespaceLister{lister: l.lister.DNSZones(namespace), class: l.class}
+}
+
+// classZoneNamespaceLister only lists and gets the zones of a controller
+// class in a namespace
+type classZoneNamespaceLister struct {
+	lister listers.DNSZoneNamespaceLister
+	class  string
+}
+
+// List lists the zones of the class in the namespace
+func (l classZoneNamespaceLister) List(selector labels.Selector) ([]*v1.DNSZone, error) {
+	zones, err := l.lister.List(selector)
+	if err != nil {
+		return nil, err
+	}
+	return classZones(zones, l.class), nil
+}
+
+// Get retrieves a zone of the class, zones of other classes are not found
+func (l classZoneNamespaceLister) Get(name string) (*v1.DNSZone, error) {
+	zone, err := l.lister.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if zone.Spec.ControllerClass != l.class {
+		return nil, errors.NewNotFound(v1.Resource("dnszone"), name)
+	}
+	return zone, nil
+}
+
+// classZones filters the zones of a controller class
+func classZones(zones []*v1.DNSZone, class string) []*v1.DNSZone {
+	var filtered []*v1.DNSZone
+	for _, zone := range zones {
+		if zone.Spec.ControllerClass == class {
+			filtered = append(filtered, zone)
+		}
+	}
+	return filtered
+}
+
+// classRecordLister only lists the records of a controller class
+type classRecordLister struct {
+	lister listers.DNSRecordLister
+	class  string
+}
+
+// List lists the records of the class
+func (l classRecordLister) List(selector labels.Selector) ([]*v1.DNSRecord, error) {
+	records, err := l.lister.List(selector)
+	if err != nil {
+		return nil, err
+	}
+	return classRecords(records, l.class), nil
+}
+
+// ByZone lists the records of the class in a zone
+func (l classRecordLister) ByZone(zoneName string) ([]*v1.DNSRecord, error) {
+	records, err := l.lister.ByZone(zoneName)
+	if err != nil {
+		return nil, err
+	}
+	return classRecords(records, l.class), nil
+}
+
+// DNSRecords returns a lister of the records of the class in a namespace
+func (l classRecordLister) DNSRecords(namespace string) listers.DNSRecordNamespaceLister {
+	return classRecordNamespaceLister{lister: l.lister.DNSRecords(namespace), class: l.class}
+}
+
+// classRecordNamespaceLister only lists and gets the records of a
+// controller class in a namespace
+type classRecordNamespaceLister struct {
+	lister listers.DNSRecordNamespaceLister
+	class  string
+}
+
+// List lists the records of the class in the namespace
+func (l classRecordNamespaceLister) List(selector labels.Selector) ([]*v1.DNSRecord, error) {
+	records, err := l.lister.List(selector)
+	if err != nil {
+		return nil, err
+	}
+	return classRecords(records, l.class), nil
+}
+
+// Get retrieves a record of the class, records of other classes are not
+// found
+func (l classRecordNamespaceLister) Get(name string) (*v1.DNSRecord, error) {
+	record, err := l.lister.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if record.Spec.ControllerClass != l.class {
+		return nil, errors.NewNotFound(v1.Resource("dnsrecord"), name)
+	}
+	return record, nil
+}
+
+// classRecords filters the records of a controller class
+func classRecords(records []*v1.DNSRecord, class string) []*v1.DNSRecord {
+	var filtered []*v1.DNSRecord
+	for _, record := range records {
+		if record.Spec.ControllerClass == class {
+			filtered = append(filtered, record)
+		}
+	}
+	return filtered
+}
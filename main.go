@@ -1,34 +1,104 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"os/signal"
+	"path"
+	"sort"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/namsral/flag"
 	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 
 	zoneclientset "github.com/estaleiro/dns-controller/pkg/client/clientset/versioned"
-	zoneinformerv1 "github.com/estaleiro/dns-controller/pkg/client/informers/externalversions/dns/v1"
+	dnsscheme "github.com/estaleiro/dns-controller/pkg/client/clientset/versioned/scheme"
+	informers "github.com/estaleiro/dns-controller/pkg/client/informers/externalversions"
 	listers "github.com/estaleiro/dns-controller/pkg/client/listers/dns/v1"
 
 	recordclientset "github.com/estaleiro/dns-controller/pkg/client/clientset/versioned"
-	recordinformerv1 "github.com/estaleiro/dns-controller/pkg/client/informers/externalversions/dns/v1"
 )
 
-// retrieve the Kubernetes cluster client from outside of the cluster
-func getKubernetesClient() (kubernetes.Interface, zoneclientset.Interface, recordclientset.Interface) {
-	kubeConfigPath := os.Getenv("HOME") + "/.kube/config"
+// clientOptions selects the cluster the controller talks to
+type clientOptions struct {
+	kubeconfig string
+	context    string
+	master     string
+	qps        float64
+	burst      int
+}
+
+// stringsFlag is a flag that can be repeated, each value may also hold a
+// comma separated list so it can be set from the environment. Empty items
+// are kept
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		*f = append(*f, strings.TrimSpace(item))
+	}
+	return nil
+}
+
+// watchedNamespaces returns the namespaces to watch sorted and without
+// duplicates, each gets its own informers. An empty namespace watches all
+// of them and makes the others redundant
+func watchedNamespaces(values []string) []string {
+	if len(values) == 0 {
+		return []string{metav1.NamespaceAll}
+	}
 
-	config, err := clientcmd.BuildConfigFromFlags("", kubeConfigPath)
+	seen := map[string]bool{}
+	var namespaces []string
+	for _, namespace := range values {
+		if namespace == metav1.NamespaceAll {
+			return []string{metav1.NamespaceAll}
+		}
+		if !seen[namespace] {
+			seen[namespace] = true
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	sort.Strings(namespaces)
+
+	return namespaces
+}
+
+// retrieve the Kubernetes cluster client, the in-cluster config is used when
+// running in a Pod and no kubeconfig is given
+func getKubernetesClient(options clientOptions) (kubernetes.Interface, zoneclientset.Interface, recordclientset.Interface) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = options.kubeconfig
+
+	overrides := &clientcmd.ConfigOverrides{
+		CurrentContext: options.context,
+		ClusterInfo:    clientcmdapi.Cluster{Server: options.master},
+	}
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
 	if err != nil {
 		log.Fatalf("getClusterConfig: %v", err)
 	}
+	config.QPS = float32(options.qps)
+	config.Burst = options.burst
 
 	client, err := kubernetes.NewForConfig(config)
 	if err != nil {
@@ -45,110 +115,296 @@ func getKubernetesClient() (kubernetes.Interface, zoneclientset.Interface, recor
 		log.Fatalf("getClusterConfig: %v", err)
 	}
 
-	log.Info("Successfully constructed k8s client")
+	log.Infof("Successfully constructed k8s client for %s", config.Host)
 	return client, zoneClient, recordClient
 }
 
+// setupLogging configures the level and format of the standard logger
+func setupLogging(level, format string) error {
+	logLevel, err := log.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	log.SetLevel(logLevel)
+
+	switch format {
+	case "text":
+		log.SetFormatter(&log.TextFormatter{})
+	case "json":
+		log.SetFormatter(&log.JSONFormatter{})
+	default:
+		return fmt.Errorf("unsupported log format %q, must be text or json", format)
+	}
+
+	return nil
+}
+
 func main() {
-	var zoneDirectory string
+	var zoneDirectory, corefilePath, upstream, httpAddress string
+	var logLevel, logFormat string
+	var namespaceValues stringsFlag
+	var labelSelector, controllerClass string
+	var webhookAddress, tlsCertFile, tlsKeyFile string
+	var dnsPort, maxRetries, workers int
+	var reconcilePeriod, progressDeadline, shutdownTimeout time.Duration
+	var debouncePeriod, debounceMaxDelay time.Duration
+	var syncDir bool
+	var clientOpts clientOptions
+	var leaderElection leaderElectionOptions
 	flagSet := flag.NewFlagSetWithEnvPrefix(os.Args[0], "COREDNS", 0)
 	flagSet.StringVar(&zoneDirectory, "zone_dir", "/tmp/zones/", "coredns zones directory path")
+	flagSet.StringVar(&corefilePath, "corefile", "", "coredns Corefile path, defaults to Corefile in zone_dir")
+	flagSet.IntVar(&dnsPort, "dns_port", 5300, "port coredns listens on")
+	flagSet.StringVar(&upstream, "upstream", "/etc/resolv.conf", "upstream resolvers for names outside the zones")
+	flagSet.IntVar(&maxRetries, "max_retries", 5, "times a failed sync is retried before giving up")
+	flagSet.IntVar(&workers, "workers", 1, "zones synced in parallel")
+	flagSet.DurationVar(&debouncePeriod, "debounce_period", time.Second, "time without changes on a zone before it is synced, 0 to sync right away")
+	flagSet.DurationVar(&debounceMaxDelay, "debounce_max_delay", 10*time.Second, "longest a zone with ongoing changes waits before it is synced")
+	flagSet.DurationVar(&reconcilePeriod, "reconcile_period", 10*time.Minute, "interval between full reconciliations of the zone directory")
+	flagSet.BoolVar(&syncDir, "fsync_dir", false, "fsync the zone directory after each write so renames survive a crash")
+	flagSet.StringVar(&clientOpts.kubeconfig, "kubeconfig", "", "kubeconfig file, defaults to $KUBECONFIG, ~/.kube/config or the in-cluster config")
+	flagSet.StringVar(&clientOpts.context, "context", "", "kubeconfig context to use")
+	flagSet.StringVar(&clientOpts.master, "master", "", "address of the Kubernetes API server, overrides the kubeconfig")
+	flagSet.Float64Var(&clientOpts.qps, "kube_api_qps", 5, "queries per second to the Kubernetes API server")
+	flagSet.IntVar(&clientOpts.burst, "kube_api_burst", 10, "burst of queries to the Kubernetes API server")
+	flagSet.BoolVar(&leaderElection.enabled, "leader_elect", false, "elect a leader among replicas, only the leader writes zone_dir")
+	flagSet.StringVar(&leaderElection.name, "leader_election_name", "dns-controller", "name of the Lease used for leader election")
+	flagSet.StringVar(&leaderElection.namespace, "leader_election_namespace", "default", "namespace of the Lease used for leader election")
+	flagSet.DurationVar(&leaderElection.leaseDuration, "lease_duration", 15*time.Second, "time standbys wait before taking over a lease that wasn't renewed")
+	flagSet.DurationVar(&leaderElection.renewDeadline, "renew_deadline", 10*time.Second, "time the leader retries renewing the lease before giving it up")
+	flagSet.DurationVar(&leaderElection.retryPeriod, "retry_period", 2*time.Second, "interval between leader election attempts")
+	flagSet.StringVar(&httpAddress, "http_address", ":8080", "address serving /metrics, /healthz and /readyz, empty to disable")
+	flagSet.DurationVar(&progressDeadline, "progress_deadline", 5*time.Minute, "time workers may go without finishing an item while zones are queued before /healthz fails")
+	flagSet.DurationVar(&shutdownTimeout, "shutdown_timeout", 30*time.Second, "time given to in-flight syncs to finish on shutdown")
+	flagSet.StringVar(&logLevel, "log-level", "info", "log level: debug, info, warn or error")
+	flagSet.StringVar(&logFormat, "log-format", "text", "log format: text or json")
+	flagSet.Var(&namespaceValues, "namespace", "namespace to watch, repeat for several, empty or unset for all namespaces")
+	flagSet.StringVar(&labelSelector, "label-selector", "", "only zones and records matching this label selector are watched")
+	flagSet.StringVar(&webhookAddress, "webhook_address", "", "address serving the admission webhook over HTTPS, empty to disable")
+	flagSet.StringVar(&tlsCertFile, "tls_cert_file", "", "certificate of the admission webhook")
+	flagSet.StringVar(&tlsKeyFile, "tls_key_file", "", "private key of the admission webhook")
+	flagSet.StringVar(&controllerClass, "controller-class", "", "only zones and records of this controllerClass are served, empty for those without a class")
 	flagSet.Parse(os.Args[1:])
+	if err := setupLogging(logLevel, logFormat); err != nil {
+		log.Fatal(err)
+	}
+	if workers < 1 {
+		log.Fatalf("workers must be at least 1, got %d", workers)
+	}
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		log.Fatalf("invalid label-selector: %v", err)
+	}
+	if errs := validation.IsQualifiedName(finalizerName(controllerClass)); len(errs) > 0 {
+		log.Fatalf("invalid controller-class, it must fit in the finalizer name: %s", strings.Join(errs, ", "))
+	}
+	if webhookAddress != "" && (tlsCertFile == "" || tlsKeyFile == "") {
+		log.Fatal("webhook_address requires tls_cert_file and tls_key_file")
+	}
+	namespaces := watchedNamespaces(namespaceValues)
+	if corefilePath == "" {
+		corefilePath = path.Join(zoneDirectory, "Corefile")
+	}
 	log.Infof("zone_dir: %s", zoneDirectory)
+	log.Infof("corefile: %s", corefilePath)
 
-	client, zoneClient, recordClient := getKubernetesClient()
+	client, zoneClient, recordClient := getKubernetesClient(clientOpts)
 
-	zoneInformer := zoneinformerv1.NewDNSZoneInformer(
-		zoneClient,
-		metav1.NamespaceAll,
-		0,
-		cache.Indexers{},
-	)
+	// a factory per watched namespace, the factories only watch one
+	// namespace or all of them
+	var informerFactories []informers.SharedInformerFactory
+	var informersSynced []cache.InformerSynced
+	var zoneInformers []cache.SharedIndexInformer
+	var recordInformers []cache.SharedIndexInformer
+	zoneListers := namespacedZoneLister{}
+	recordListers := namespacedRecordLister{}
+	for _, namespace := range namespaces {
+		factory := informers.NewSharedInformerFactoryWithOptions(
+			zoneClient,
+			0,
+			informers.WithNamespace(namespace),
+			informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+				options.LabelSelector = labelSelector
+			}),
+		)
 
-	recordInformer := recordinformerv1.NewDNSRecordInformer(
-		recordClient,
-		metav1.NamespaceAll,
-		0,
-		cache.Indexers{},
-	)
+		zoneInformer := factory.Estaleiro().V1().DNSZones()
+		recordInformer := factory.Estaleiro().V1().DNSRecords()
 
-	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		// records are looked up by the zone they belong to
+		err := recordInformer.Informer().AddIndexers(cache.Indexers{listers.DNSRecordZoneIndex: listers.DNSRecordZoneIndexFunc})
+		if err != nil {
+			log.Fatalf("error indexing records: %v", err)
+		}
 
-	recordDeletedIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+		informerFactories = append(informerFactories, factory)
+		informersSynced = append(informersSynced, zoneInformer.Informer().HasSynced, recordInformer.Informer().HasSynced)
+		zoneInformers = append(zoneInformers, zoneInformer.Informer())
+		recordInformers = append(recordInformers, recordInformer.Informer())
+		zoneListers[namespace] = zoneInformer.Lister()
+		recordListers[namespace] = recordInformer.Lister()
+	}
 
-	zoneDeletedIndexer := cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{})
+	// a single namespace, or all of them, needs no merging
+	var zoneLister listers.DNSZoneLister = zoneListers
+	var recordLister listers.DNSRecordLister = recordListers
+	if len(namespaces) == 1 {
+		zoneLister = zoneListers[namespaces[0]]
+		recordLister = recordListers[namespaces[0]]
+	}
 
-	recordInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc: func(obj interface{}) {
-			key, err := cache.MetaNamespaceKeyFunc(obj)
-			log.Infof("Add record: %s", key)
-			if err == nil {
-				queue.Add(DNSResource{Key: key, Type: Record})
-			}
-		},
-		UpdateFunc: func(oldObj, newObj interface{}) {
-			key, err := cache.MetaNamespaceKeyFunc(newObj)
-			log.Infof("Update record: %s", key)
-			if err == nil {
-				queue.Add(DNSResource{Key: key, Type: Record})
-			}
-		},
-		DeleteFunc: func(obj interface{}) {
-			key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
-			log.Infof("Delete record: %s", key)
-			if err == nil {
-				recordDeletedIndexer.Add(obj)
-				queue.Add(DNSResource{Key: key, Type: Record})
-			}
-		},
+	// objects of other classes belong to other instances
+	zoneLister = classZoneLister{lister: zoneLister, class: controllerClass}
+	recordLister = classRecordLister{lister: recordLister, class: controllerClass}
+
+	// the queue only reports metrics when created after they are registered
+	registerMetrics(zoneLister, recordLister)
+
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "dns-controller")
+
+	// bursts of changes on a zone are synced once
+	debouncer := newDebouncer(debouncePeriod, debounceMaxDelay, func(key string) {
+		queue.Add(key)
 	})
 
-	zoneInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc: func(obj interface{}) {
-			key, err := cache.MetaNamespaceKeyFunc(obj)
-			log.Infof("Add zone: %s", key)
-			if err == nil {
-				queue.Add(DNSResource{Key: key, Type: Zone})
-			}
-		},
+	// zones and records are queued under the name of their zone, an object
+	// moving to another class is queued once more to remove it
+	enqueue := func(obj interface{}) {
+		if objectClass(obj) != controllerClass {
+			return
+		}
+		key, resourceType, ok := zoneQueueKey(obj)
+		log.WithFields(log.Fields{"zone": key, "resource": resourceType.String()}).Debug("queue zone")
+		if ok {
+			debouncer.Add(key)
+		}
+	}
+
+	// objects leaving the instance keep its finalizer until a worker removes
+	// it, the worker checks the object really left
+	release := func(obj interface{}) {
+		if key, ok := releaseQueueKey(obj); ok {
+			queue.Add(key)
+		}
+	}
+
+	eventHandler := cache.ResourceEventHandlerFuncs{
+		AddFunc: enqueue,
 		UpdateFunc: func(oldObj, newObj interface{}) {
-			key, err := cache.MetaNamespaceKeyFunc(newObj)
-			log.Infof("Update zone: %s", key)
-			if err == nil {
-				queue.Add(DNSResource{Key: key, Type: Zone})
+			// an object moving to another zone leaves the previous one
+			enqueue(oldObj)
+			enqueue(newObj)
+			if objectClass(oldObj) == controllerClass && objectClass(newObj) != controllerClass {
+				release(newObj)
 			}
 		},
 		DeleteFunc: func(obj interface{}) {
-			key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
-			log.Infof("Delete zone: %s", key)
-			if err == nil {
-				zoneDeletedIndexer.Add(obj)
-				queue.Add(DNSResource{Key: key, Type: Zone})
+			enqueue(obj)
+			// objects whose labels stop matching label-selector are
+			// deleted from the informers
+			if objectClass(obj) == controllerClass {
+				release(obj)
 			}
 		},
-	})
+	}
+
+	for _, informer := range append(zoneInformers, recordInformers...) {
+		informer.AddEventHandler(eventHandler)
+	}
+
+	// events are recorded on our own resources too
+	dnsscheme.AddToScheme(scheme.Scheme)
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartLogging(log.Debugf)
+	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events("")})
+	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: "dns-controller"})
+
+	zoneHandler := &ZoneHandler{
+		zoneDirectory: zoneDirectory,
+		corefile:      corefilePath,
+		dnsPort:       dnsPort,
+		upstream:      upstream,
+		zoneClientset: zoneClient,
+		zoneLister:    zoneLister,
+		recordLister:  recordLister,
+		syncDir:       syncDir,
+	}
 
 	controller := Controller{
-		logger:               log.NewEntry(log.New()),
-		clientset:            client,
-		zoneInformer:         zoneInformer,
-		zoneLister:           listers.NewDNSZoneLister(zoneInformer.GetIndexer()),
-		recordInformer:       recordInformer,
-		recordLister:         listers.NewDNSRecordLister(recordInformer.GetIndexer()),
-		queue:                queue,
-		zoneHandler:          &ZoneHandler{zoneDirectory: zoneDirectory},
-		recordHandler:        &RecordHandler{zoneDirectory: zoneDirectory},
-		zoneDeletedIndexer:   zoneDeletedIndexer,
-		recordDeletedIndexer: recordDeletedIndexer,
+		logger:            log.NewEntry(log.StandardLogger()),
+		clientset:         client,
+		zoneClientset:     zoneClient,
+		recordClientset:   recordClient,
+		informerFactories: informerFactories,
+		informersSynced:   informersSynced,
+		zoneLister:        zoneLister,
+		recordLister:      recordLister,
+		queue:             queue,
+		zoneHandler:       zoneHandler,
+		recordHandler:     &RecordHandler{},
+		recorder:          recorder,
+		maxRetries:        maxRetries,
+		controllerClass:   controllerClass,
+		finalizer:         finalizerName(controllerClass),
+		namespaces:        namespaces,
+		selector:          selector,
+		reconcilePeriod:   reconcilePeriod,
+		workers:           workers,
+		debouncer:         debouncer,
+	}
+
+	if httpAddress != "" {
+		go serveHTTP(httpAddress, controller.liveness(progressDeadline), controller.readiness(zoneDirectory))
+	}
+
+	// standbys validate too, they have the same caches as the leader
+	if webhookAddress != "" {
+		webhook := &admissionWebhook{
+			zoneLister:   zoneLister,
+			recordLister: recordLister,
+			class:        controllerClass,
+			hasSynced:    controller.HasSynced,
+		}
+		go serveWebhook(webhookAddress, tlsCertFile, tlsKeyFile, webhook)
 	}
 
 	stopCh := make(chan struct{})
-	defer close(stopCh)
 
-	go controller.Run(stopCh)
+	// closed once the workers are drained and the lease released
+	stopped := make(chan struct{})
+
+	// standbys keep their caches warm, only the leader runs the workers
+	controller.RunInformers(stopCh)
+	if leaderElection.enabled {
+		hostname, err := os.Hostname()
+		if err != nil {
+			log.Fatalf("leader election: %v", err)
+		}
+		leaderElection.identity = hostname
+
+		go func() {
+			defer close(stopped)
+			runLeaderElection(leaderElection, client, recorder, controller.Run, stopCh)
+		}()
+	} else {
+		go func() {
+			defer close(stopped)
+			controller.Run(stopCh)
+		}()
+	}
 
 	sigTerm := make(chan os.Signal, 1)
 	signal.Notify(sigTerm, syscall.SIGTERM)
 	signal.Notify(sigTerm, syscall.SIGINT)
 	<-sigTerm
+
+	log.Info("shutting down")
+	close(stopCh)
+
+	select {
+	case <-stopped:
+		log.Info("shutdown complete")
+	case <-time.After(shutdownTimeout):
+		log.Warnf("shutdown timed out after %v", shutdownTimeout)
+	}
 }
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"time"
+
+	listers "github.com/estaleiro/dns-controller/pkg/client/listers/dns/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// metricsNamespace prefixes the name of every controller metric
+const metricsNamespace = "dns_controller"
+
+// Controller metrics, see registerMetrics
+var (
+	syncsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "syncs_total",
+		Help:      "Zone and record syncs by type and result.",
+	}, []string{"type", "result"})
+
+	syncDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "sync_duration_seconds",
+		Help:      "Duration of zone and record syncs by type.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"type"})
+
+	syncFailuresTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "sync_failures_total",
+		Help:      "Zones given up on after all retries.",
+	})
+
+	renderDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "zone_render_duration_seconds",
+		Help:      "Duration of zone file renders, writes included.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	renderErrorsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "zone_render_errors_total",
+		Help:      "Zone files that could not be rendered or written.",
+	})
+
+	lastSyncTime = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "last_successful_sync_timestamp_seconds",
+		Help:      "Unix time of the last successful sync of each zone.",
+	}, []string{"zone"})
+)
+
+// registerMetrics registers the controller and workqueue metrics, it must
+// run before the queue is created for the queue to report its metrics
+func registerMetrics(zoneLister listers.DNSZoneLister, recordLister listers.DNSRecordLister) {
+	zonesTotal := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "zones",
+		Help:      "DNSZone objects known to the controller.",
+	}, func() float64 {
+		zones, err := zoneLister.List(labels.Everything())
+		if err != nil {
+			return 0
+		}
+		return float64(len(zones))
+	})
+
+	recordsTotal := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "records",
+		Help:      "DNSRecord objects known to the controller.",
+	}, func() float64 {
+		records, err := recordLister.List(labels.Everything())
+		if err != nil {
+			return 0
+		}
+		return float64(len(records))
+	})
+
+	prometheus.MustRegister(syncsTotal, syncDuration, syncFailuresTotal, renderDuration, renderErrorsTotal, lastSyncTime, zonesTotal, recordsTotal)
+
+	workqueue.SetProvider(newWorkqueueMetricsProvider())
+}
+
+// observeSync records the outcome and duration of a sync
+func observeSync(resourceType DNSResourceType, start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+
+	syncsTotal.WithLabelValues(resourceType.String(), result).Inc()
+	syncDuration.WithLabelValues(resourceType.String()).Observe(time.Since(start).Seconds())
+}
+
+// workqueueMetricsProvider exports the metrics of named workqueues, client-go
+// only provides the interface
+type workqueueMetricsProvider struct {
+	depth                   *prometheus.GaugeVec
+	adds                    *prometheus.CounterVec
+	latency                 *prometheus.SummaryVec
+	workDuration            *prometheus.SummaryVec
+	unfinishedWork          *prometheus.GaugeVec
+	longestRunningProcessor *prometheus.GaugeVec
+	retries                 *prometheus.CounterVec
+}
+
+func newWorkqueueMetricsProvider() *workqueueMetricsProvider {
+	p := &workqueueMetricsProvider{
+		depth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Subsystem: "workqueue",
+			Name:      "depth",
+			Help:      "Current depth of the workqueue.",
+		}, []string{"name"}),
+		adds: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Subsystem: "workqueue",
+			Name:      "adds_total",
+			Help:      "Adds handled by the workqueue.",
+		}, []string{"name"}),
+		latency: prometheus.NewSummaryVec(prometheus.SummaryOpts{
+			Subsystem: "workqueue",
+			Name:      "queue_latency_microseconds",
+			Help:      "Time an item stays in the workqueue before being requested.",
+		}, []string{"name"}),
+		workDuration: prometheus.NewSummaryVec(prometheus.SummaryOpts{
+			Subsystem: "workqueue",
+			Name:      "work_duration_microseconds",
+			Help:      "Time processing an item from the workqueue takes.",
+		}, []string{"name"}),
+		unfinishedWork: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Subsystem: "workqueue",
+			Name:      "unfinished_work_seconds",
+			Help:      "Seconds of work in progress not yet observed by work_duration.",
+		}, []string{"name"}),
+		longestRunningProcessor: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Subsystem: "workqueue",
+			Name:      "longest_running_processor_microseconds",
+			Help:      "Microseconds the longest running processor has been running.",
+		}, []string{"name"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Subsystem: "workqueue",
+			Name:      "retries_total",
+			Help:      "Retries handled by the workqueue.",
+		}, []string{"name"}),
+	}
+
+	prometheus.MustRegister(p.depth, p.adds, p.latency, p.workDuration, p.unfinishedWork, p.longestRunningProcessor, p.retries)
+
+	return p
+}
+
+func (p *workqueueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	return p.depth.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	return p.adds.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewLatencyMetric(name string) workqueue.SummaryMetric {
+	return p.latency.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewWorkDurationMetric(name string) workqueue.SummaryMetric {
+	return p.workDuration.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return p.unfinishedWork.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewLongestRunningProcessorMicrosecondsMetric(name string) workqueue.SettableGaugeMetric {
+	return p.longestRunningProcessor.WithLabelValues(name)
+}
+
+func (p *workqueueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	return p.retries.WithLabelValues(name)
+}
@@ -1,12 +1,19 @@
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // +genclient
-// +genclient:noStatus
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName=dz
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Serial",type=integer,JSONPath=`.status.serial`
+// +kubebuilder:printcolumn:name="Records",type=integer,JSONPath=`.status.recordCount`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // DNSZone describes a DNSZone resource
 type DNSZone struct {
@@ -16,14 +23,91 @@ type DNSZone struct {
 
 	// Spec is the custom resource spec
 	Spec DNSZoneSpec `json:"spec"`
+	// Status is the custom resource status
+	Status DNSZoneStatus `json:"status,omitempty"`
 }
 
 // DNSZoneSpec is the spec for a DNSZone resource
 type DNSZoneSpec struct {
+	// ZoneName is the DNS name of the zone, defaults to the object name
+	// +optional
+	// +kubebuilder:validation:MaxLength=254
+	// +kubebuilder:validation:Pattern=`^(([A-Za-z0-9_-]{1,63}\.)*[A-Za-z0-9_-]{1,63}\.?)?$`
 	ZoneName string `json:"zoneName"`
-	Refresh int `json:"refresh"`
-	Retry int `json:"retry"`
-	Expire int `json:"expire"`
+	// Refresh is the SOA refresh time in seconds
+	// +optional
+	// +kubebuilder:default=3600
+	// +kubebuilder:validation:Minimum=30
+	// +kubebuilder:validation:Maximum=2147483647
+	Refresh int `json:"refresh,omitempty"`
+	// Retry is the SOA retry time in seconds
+	// +optional
+	// +kubebuilder:default=600
+	// +kubebuilder:validation:Minimum=30
+	// +kubebuilder:validation:Maximum=2147483647
+	Retry int `json:"retry,omitempty"`
+	// Expire is the SOA expire time in seconds
+	// +optional
+	// +kubebuilder:default=604800
+	// +kubebuilder:validation:Minimum=30
+	// +kubebuilder:validation:Maximum=2147483647
+	Expire int `json:"expire,omitempty"`
+	// Minimum is the SOA minimum, used as the negative caching TTL
+	// +kubebuilder:default=3600
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=2147483647
+	Minimum int `json:"minimum,omitempty"`
+	// TTL is the default TTL of the zone records
+	// +kubebuilder:default=3600
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=2147483647
+	TTL int `json:"ttl,omitempty"`
+	// PrimaryNS is the primary name server written in the SOA record, an
+	// absolute name with or without the trailing dot
+	PrimaryNS string `json:"primaryNS,omitempty"`
+	// NameServers are the NS records of the zone apex as absolute names,
+	// defaults to PrimaryNS
+	NameServers []string `json:"nameServers,omitempty"`
+	// Hostmaster is the zone contact, either as an email address or as
+	// a mailbox domain name
+	Hostmaster string `json:"hostmaster,omitempty"`
+	// SerialStrategy is how the SOA serial is incremented, defaults to date
+	// +kubebuilder:default=date
+	SerialStrategy SerialStrategy `json:"serialStrategy,omitempty"`
+	// ControllerClass is the class of the controller instance serving the
+	// zone, empty for the instance started without a class
+	ControllerClass string `json:"controllerClass,omitempty"`
+	// AllowedNamespaces are the namespaces whose records may use the zone
+	// besides its own, every namespace may when empty
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+}
+
+// SerialStrategy defines how the SOA serial of a zone is incremented
+// +kubebuilder:validation:Enum=date;unixtime;counter
+type SerialStrategy string
+
+// Supported serial strategies
+const (
+	// SerialStrategyDate uses YYYYMMDDnn serials
+	SerialStrategyDate SerialStrategy = "date"
+	// SerialStrategyUnixTime uses the unix timestamp of the change
+	SerialStrategyUnixTime SerialStrategy = "unixtime"
+	// SerialStrategyCounter increments the serial by one
+	SerialStrategyCounter SerialStrategy = "counter"
+)
+
+// DNSZoneStatus is the status for a DNSZone resource
+type DNSZoneStatus struct {
+	// ObservedGeneration is the generation the status was computed for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions are the Ready, Conflict and Invalid conditions
+	Conditions []Condition `json:"conditions,omitempty"`
+	// File is the path of the rendered master file
+	File string `json:"file,omitempty"`
+	// Serial is the SOA serial of the rendered master file
+	Serial uint32 `json:"serial,omitempty"`
+	// RecordCount is the number of DNSRecords rendered in the zone
+	RecordCount int `json:"recordCount,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -37,31 +121,123 @@ type DNSZoneList struct {
 }
 
 // +genclient
-// +genclient:noStatus
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:shortName=dr
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Zone",type=string,JSONPath=`.spec.zoneName`
+// +kubebuilder:printcolumn:name="Name",type=string,JSONPath=`.spec.name`
+// +kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.type`
+// +kubebuilder:printcolumn:name="Value",type=string,JSONPath=`.spec.value`
+// +kubebuilder:printcolumn:name="TTL",type=integer,JSONPath=`.spec.ttl`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // DNSRecord describes a DNSRecord resource
 type DNSRecord struct {
-        // TypeMeta is the metadata for the resource, like kind and apiversion
-        metav1.TypeMeta   `json:",inline"`
-        metav1.ObjectMeta `json:"metadata,omitempty"`
+	// TypeMeta is the metadata for the resource, like kind and apiversion
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-        // Spec is the custom resource spec
-        Spec DNSRecordSpec `json:"spec"`
+	// Spec is the custom resource spec
+	Spec DNSRecordSpec `json:"spec"`
+	// Status is the custom resource status
+	Status DNSRecordStatus `json:"status,omitempty"`
 }
 
 // DNSRecordSpec is the spec for a DNSRecord resource
 type DNSRecordSpec struct {
-        ZoneName string `json:"zoneName"`
+	// ZoneName is the DNS name of the zone the record belongs to
+	// +kubebuilder:validation:MaxLength=254
+	// +kubebuilder:validation:Pattern=`^([A-Za-z0-9_-]{1,63}\.)*[A-Za-z0-9_-]{1,63}\.?$`
+	ZoneName string `json:"zoneName"`
+	// Name is the owner name relative to the zone, "@" for the zone apex.
+	// Names ending with a dot are absolute and must be inside the zone
+	Name string `json:"name"`
+	// Type is the record type
+	Type RecordType `json:"type"`
+	// TTL in seconds, zero uses the zone default
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=2147483647
+	TTL int `json:"ttl,omitempty"`
+	// Value is the record data: an address for A and AAAA, a host name
+	// for CNAME, MX, NS, PTR and SRV, and free text for TXT. Host names are
+	// absolute, the trailing dot is optional
+	Value string `json:"value"`
+	// Priority is the MX preference or the SRV priority
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	Priority int `json:"priority,omitempty"`
+	// Weight is the SRV weight
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	Weight int `json:"weight,omitempty"`
+	// Port is the SRV port
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=65535
+	Port int `json:"port,omitempty"`
+	// ControllerClass is the class of the controller instance serving the
+	// record, it must match the class of its zone
+	ControllerClass string `json:"controllerClass,omitempty"`
+}
+
+// RecordType is the type of a DNSRecord
+// +kubebuilder:validation:Enum=A;AAAA;CNAME;TXT;MX;SRV;NS;PTR
+type RecordType string
+
+// Supported record types
+const (
+	RecordTypeA     RecordType = "A"
+	RecordTypeAAAA  RecordType = "AAAA"
+	RecordTypeCNAME RecordType = "CNAME"
+	RecordTypeTXT   RecordType = "TXT"
+	RecordTypeMX    RecordType = "MX"
+	RecordTypeSRV   RecordType = "SRV"
+	RecordTypeNS    RecordType = "NS"
+	RecordTypePTR   RecordType = "PTR"
+)
+
+// DNSRecordStatus is the status for a DNSRecord resource
+type DNSRecordStatus struct {
+	// ObservedGeneration is the generation the status was computed for
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions are the Ready, Conflict and Invalid conditions
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+// ConditionType is the type of a status condition
+type ConditionType string
+
+// Condition types of DNSZone and DNSRecord
+const (
+	// ConditionReady is true when the resource is served
+	ConditionReady ConditionType = "Ready"
+	// ConditionConflict is true when another resource takes precedence
+	ConditionConflict ConditionType = "Conflict"
+	// ConditionInvalid is true when the spec can't be rendered
+	ConditionInvalid ConditionType = "Invalid"
+)
+
+// Condition describes the state of a resource at a point in time
+type Condition struct {
+	// Type is Ready, Conflict or Invalid
+	Type ConditionType `json:"type"`
+	// Status is True, False or Unknown
+	Status corev1.ConditionStatus `json:"status"`
+	// LastTransitionTime is the last time the status changed
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Reason is a CamelCase reason for the last transition
+	Reason string `json:"reason,omitempty"`
+	// Message is a human readable explanation of the last transition
+	Message string `json:"message,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // DNSRecordList is a list of Record resources
 type DNSRecordList struct {
-        metav1.TypeMeta `json:",inline"`
-        metav1.ListMeta `json:"metadata"`
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
 
-        Items []DNSRecord `json:"items"`
+	Items []DNSRecord `json:"items"`
 }
-
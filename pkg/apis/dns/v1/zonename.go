@@ -0,0 +1,13 @@
+package v1
+
+import "strings"
+
+// NormalizeZoneName returns a zone name as a lowercase fully qualified
+// domain name, the form zones are matched and indexed by
+func NormalizeZoneName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return name
+}
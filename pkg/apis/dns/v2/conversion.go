@@ -0,0 +1,243 @@
+package v2
+
+import (
+	"encoding/json"
+	"reflect"
+
+	v1 "github.com/estaleiro/dns-controller/pkg/apis/dns/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RecordsAnnotation holds the records of a v2 record set on the v1 object
+// when the v1 fields can't, so converting back to v2 loses nothing
+const RecordsAnnotation = "estaleiro.io/records"
+
+// ConvertZoneFromV1 converts a v1 DNSZone to v2
+func ConvertZoneFromV1(in *v1.DNSZone) *DNSZone {
+	out := &DNSZone{}
+	out.APIVersion = SchemeGroupVersion.String()
+	out.Kind = "DNSZone"
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+
+	out.Spec = DNSZoneSpec{
+		ZoneName:          in.Spec.ZoneName,
+		Refresh:           in.Spec.Refresh,
+		Retry:             in.Spec.Retry,
+		Expire:            in.Spec.Expire,
+		Minimum:           in.Spec.Minimum,
+		TTL:               in.Spec.TTL,
+		PrimaryNS:         in.Spec.PrimaryNS,
+		NameServers:       copyStrings(in.Spec.NameServers),
+		Hostmaster:        in.Spec.Hostmaster,
+		SerialStrategy:    SerialStrategy(in.Spec.SerialStrategy),
+		ControllerClass:   in.Spec.ControllerClass,
+		AllowedNamespaces: copyStrings(in.Spec.AllowedNamespaces),
+	}
+
+	out.Status = DNSZoneStatus{
+		ObservedGeneration: in.Status.ObservedGeneration,
+		Conditions:         conditionsFromV1(in.Status.Conditions),
+		File:               in.Status.File,
+		Serial:             in.Status.Serial,
+		RecordCount:        in.Status.RecordCount,
+	}
+
+	return out
+}
+
+// ConvertZoneToV1 converts a v2 DNSZone to v1
+func ConvertZoneToV1(in *DNSZone) *v1.DNSZone {
+	out := &v1.DNSZone{}
+	out.APIVersion = v1.SchemeGroupVersion.String()
+	out.Kind = "DNSZone"
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+
+	out.Spec = v1.DNSZoneSpec{
+		ZoneName:          in.Spec.ZoneName,
+		Refresh:           in.Spec.Refresh,
+		Retry:             in.Spec.Retry,
+		Expire:            in.Spec.Expire,
+		Minimum:           in.Spec.Minimum,
+		TTL:               in.Spec.TTL,
+		PrimaryNS:         in.Spec.PrimaryNS,
+		NameServers:       copyStrings(in.Spec.NameServers),
+		Hostmaster:        in.Spec.Hostmaster,
+		SerialStrategy:    v1.SerialStrategy(in.Spec.SerialStrategy),
+		ControllerClass:   in.Spec.ControllerClass,
+		AllowedNamespaces: copyStrings(in.Spec.AllowedNamespaces),
+	}
+
+	out.Status = v1.DNSZoneStatus{
+		ObservedGeneration: in.Status.ObservedGeneration,
+		Conditions:         conditionsToV1(in.Status.Conditions),
+		File:               in.Status.File,
+		Serial:             in.Status.Serial,
+		RecordCount:        in.Status.RecordCount,
+	}
+
+	return out
+}
+
+// ConvertRecordFromV1 converts a v1 DNSRecord to a v2 record set, the
+// records kept in RecordsAnnotation are restored unless the v1 fields
+// changed since
+func ConvertRecordFromV1(in *v1.DNSRecord) *DNSRecord {
+	out := &DNSRecord{}
+	out.APIVersion = SchemeGroupVersion.String()
+	out.Kind = "DNSRecord"
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	removeRecordsAnnotation(&out.ObjectMeta)
+
+	records := recordsFromV1(in.Spec)
+	if value, ok := in.Annotations[RecordsAnnotation]; ok {
+		var kept []RecordData
+		if err := json.Unmarshal([]byte(value), &kept); err == nil && reflect.DeepEqual(recordsToV1(in.Spec, kept), in.Spec) {
+			records = kept
+		}
+	}
+
+	out.Spec = DNSRecordSpec{
+		ZoneName:        in.Spec.ZoneName,
+		Name:            in.Spec.Name,
+		Type:            RecordType(in.Spec.Type),
+		TTL:             in.Spec.TTL,
+		Records:         records,
+		ControllerClass: in.Spec.ControllerClass,
+	}
+
+	out.Status = DNSRecordStatus{
+		ObservedGeneration: in.Status.ObservedGeneration,
+		Conditions:         conditionsFromV1(in.Status.Conditions),
+	}
+
+	return out
+}
+
+// ConvertRecordToV1 converts a v2 record set to a v1 DNSRecord holding its
+// first record, the records v1 can't hold are kept in RecordsAnnotation
+func ConvertRecordToV1(in *DNSRecord) *v1.DNSRecord {
+	out := &v1.DNSRecord{}
+	out.APIVersion = v1.SchemeGroupVersion.String()
+	out.Kind = "DNSRecord"
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	removeRecordsAnnotation(&out.ObjectMeta)
+
+	spec := v1.DNSRecordSpec{
+		ZoneName:        in.Spec.ZoneName,
+		Name:            in.Spec.Name,
+		Type:            v1.RecordType(in.Spec.Type),
+		TTL:             in.Spec.TTL,
+		ControllerClass: in.Spec.ControllerClass,
+	}
+	out.Spec = recordsToV1(spec, in.Spec.Records)
+
+	if !reflect.DeepEqual(recordsFromV1(out.Spec), in.Spec.Records) {
+		kept, _ := json.Marshal(in.Spec.Records)
+		if out.Annotations == nil {
+			out.Annotations = map[string]string{}
+		}
+		out.Annotations[RecordsAnnotation] = string(kept)
+	}
+
+	out.Status = v1.DNSRecordStatus{
+		ObservedGeneration: in.Status.ObservedGeneration,
+		Conditions:         conditionsToV1(in.Status.Conditions),
+	}
+
+	return out
+}
+
+// removeRecordsAnnotation drops a RecordsAnnotation left by an earlier
+// conversion
+func removeRecordsAnnotation(meta *metav1.ObjectMeta) {
+	if _, ok := meta.Annotations[RecordsAnnotation]; !ok {
+		return
+	}
+	delete(meta.Annotations, RecordsAnnotation)
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+}
+
+// recordsFromV1 returns the single record a v1 spec holds
+func recordsFromV1(spec v1.DNSRecordSpec) []RecordData {
+	data := RecordData{
+		Priority: spec.Priority,
+		Weight:   spec.Weight,
+		Port:     spec.Port,
+	}
+	*dataField(&data, spec.Type) = spec.Value
+
+	return []RecordData{data}
+}
+
+// recordsToV1 sets the first of records in a copy of spec
+func recordsToV1(spec v1.DNSRecordSpec, records []RecordData) v1.DNSRecordSpec {
+	spec.Value, spec.Priority, spec.Weight, spec.Port = "", 0, 0, 0
+	if len(records) == 0 {
+		return spec
+	}
+
+	data := records[0]
+	spec.Value = *dataField(&data, spec.Type)
+	spec.Priority = data.Priority
+	spec.Weight = data.Weight
+	spec.Port = data.Port
+
+	return spec
+}
+
+// dataField returns the field of data holding the v1 value of a record type
+func dataField(data *RecordData, recordType v1.RecordType) *string {
+	switch recordType {
+	case v1.RecordTypeA, v1.RecordTypeAAAA:
+		return &data.Address
+	case v1.RecordTypeCNAME, v1.RecordTypeMX, v1.RecordTypeNS, v1.RecordTypePTR, v1.RecordTypeSRV:
+		return &data.Host
+	default:
+		return &data.Text
+	}
+}
+
+func conditionsFromV1(in []v1.Condition) []Condition {
+	if in == nil {
+		return nil
+	}
+
+	out := make([]Condition, 0, len(in))
+	for _, condition := range in {
+		out = append(out, Condition{
+			Type:               ConditionType(condition.Type),
+			Status:             condition.Status,
+			LastTransitionTime: condition.LastTransitionTime,
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+		})
+	}
+	return out
+}
+
+func conditionsToV1(in []Condition) []v1.Condition {
+	if in == nil {
+		return nil
+	}
+
+	out := make([]v1.Condition, 0, len(in))
+	for _, condition := range in {
+		out = append(out, v1.Condition{
+			Type:               v1.ConditionType(condition.Type),
+			Status:             condition.Status,
+			LastTransitionTime: condition.LastTransitionTime,
+			Reason:             condition.Reason,
+			Message:            condition.Message,
+		})
+	}
+	return out
+}
+
+func copyStrings(in []string) []string {
+	if in == nil {
+		return nil
+	}
+	return append([]string{}, in...)
+}
@@ -0,0 +1,187 @@
+package v2
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"testing"
+
+	v1 "github.com/estaleiro/dns-controller/pkg/apis/dns/v1"
+	fuzz "github.com/google/gofuzz"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const fuzzIterations = 1000
+
+// fuzzSeeds are fuzzed on every run so failures reproduce, -seed fuzzes
+// another seed instead
+var fuzzSeeds = []int64{1, 42, 20181221}
+
+var fuzzSeed = flag.Int64("seed", 0, "fuzz this seed instead of the fixed ones")
+
+var recordTypes = []RecordType{
+	RecordTypeA, RecordTypeAAAA, RecordTypeCNAME, RecordTypeTXT,
+	RecordTypeMX, RecordTypeSRV, RecordTypeNS, RecordTypePTR,
+}
+
+// forEachSeed runs test with a fuzzer of each seed
+func forEachSeed(t *testing.T, test func(t *testing.T, f *fuzz.Fuzzer)) {
+	seeds := fuzzSeeds
+	if *fuzzSeed != 0 {
+		seeds = []int64{*fuzzSeed}
+	}
+
+	for _, seed := range seeds {
+		seed := seed
+		t.Run(fmt.Sprintf("seed=%d", seed), func(t *testing.T) {
+			test(t, newFuzzer(seed))
+		})
+	}
+}
+
+// newFuzzer returns a fuzzer filling objects the way the API server would
+// hand them to the conversion webhook, RecordsAnnotation is only ever set
+// by a conversion and is covered by the annotation tests
+func newFuzzer(seed int64) *fuzz.Fuzzer {
+	return fuzz.NewWithSeed(seed).NilChance(0.3).NumElements(0, 3).Funcs(
+		func(meta *metav1.ObjectMeta, c fuzz.Continue) {
+			c.FuzzNoCustom(meta)
+			delete(meta.Annotations, RecordsAnnotation)
+			// annotations are omitted when empty, the webhook never sees an
+			// empty map
+			if len(meta.Annotations) == 0 {
+				meta.Annotations = nil
+			}
+		},
+		func(recordType *RecordType, c fuzz.Continue) {
+			*recordType = recordTypes[c.Intn(len(recordTypes))]
+		},
+		func(recordType *v1.RecordType, c fuzz.Continue) {
+			*recordType = v1.RecordType(recordTypes[c.Intn(len(recordTypes))])
+		},
+	)
+}
+
+func TestZoneRoundTripFromV1(t *testing.T) {
+	forEachSeed(t, func(t *testing.T, f *fuzz.Fuzzer) {
+		for i := 0; i < fuzzIterations; i++ {
+			in := &v1.DNSZone{}
+			f.Fuzz(in)
+			in.APIVersion, in.Kind = v1.SchemeGroupVersion.String(), "DNSZone"
+
+			if out := ConvertZoneToV1(ConvertZoneFromV1(in)); !reflect.DeepEqual(in, out) {
+				t.Fatalf("v1 -> v2 -> v1 changed the zone:\n%#v\n%#v", in, out)
+			}
+		}
+	})
+}
+
+func TestZoneRoundTripFromV2(t *testing.T) {
+	forEachSeed(t, func(t *testing.T, f *fuzz.Fuzzer) {
+		for i := 0; i < fuzzIterations; i++ {
+			in := &DNSZone{}
+			f.Fuzz(in)
+			in.APIVersion, in.Kind = SchemeGroupVersion.String(), "DNSZone"
+
+			if out := ConvertZoneFromV1(ConvertZoneToV1(in)); !reflect.DeepEqual(in, out) {
+				t.Fatalf("v2 -> v1 -> v2 changed the zone:\n%#v\n%#v", in, out)
+			}
+		}
+	})
+}
+
+func TestRecordRoundTripFromV1(t *testing.T) {
+	forEachSeed(t, func(t *testing.T, f *fuzz.Fuzzer) {
+		for i := 0; i < fuzzIterations; i++ {
+			in := &v1.DNSRecord{}
+			f.Fuzz(in)
+			in.APIVersion, in.Kind = v1.SchemeGroupVersion.String(), "DNSRecord"
+
+			converted := ConvertRecordFromV1(in)
+			if len(converted.Spec.Records) != 1 {
+				t.Fatalf("v1 record converted to %d records: %#v", len(converted.Spec.Records), converted)
+			}
+			if out := ConvertRecordToV1(converted); !reflect.DeepEqual(in, out) {
+				t.Fatalf("v1 -> v2 -> v1 changed the record:\n%#v\n%#v", in, out)
+			}
+		}
+	})
+}
+
+func TestRecordRoundTripFromV2(t *testing.T) {
+	forEachSeed(t, func(t *testing.T, f *fuzz.Fuzzer) {
+		for i := 0; i < fuzzIterations; i++ {
+			in := &DNSRecord{}
+			f.Fuzz(in)
+			in.APIVersion, in.Kind = SchemeGroupVersion.String(), "DNSRecord"
+
+			converted := ConvertRecordToV1(in)
+			if out := ConvertRecordFromV1(converted); !reflect.DeepEqual(in, out) {
+				t.Fatalf("v2 -> v1 -> v2 changed the record:\n%#v\n%#v\nv1: %#v", in, out, converted)
+			}
+		}
+	})
+}
+
+func TestRecordsAnnotation(t *testing.T) {
+	set := &DNSRecord{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "mail",
+			Annotations: map[string]string{"team": "infra"},
+		},
+		Spec: DNSRecordSpec{
+			ZoneName: "example.com.",
+			Name:     "@",
+			Type:     RecordTypeMX,
+			Records: []RecordData{
+				{Host: "mx1.example.com.", Priority: 10},
+				{Host: "mx2.example.com.", Priority: 20},
+			},
+		},
+	}
+
+	stored := ConvertRecordToV1(set)
+	if stored.Spec.Value != "mx1.example.com." || stored.Spec.Priority != 10 {
+		t.Errorf("v1 record doesn't hold the first record: %#v", stored.Spec)
+	}
+	if _, ok := stored.Annotations[RecordsAnnotation]; !ok {
+		t.Fatalf("record set of two records stored without %s: %#v", RecordsAnnotation, stored.Annotations)
+	}
+	if stored.Annotations["team"] != "infra" {
+		t.Errorf("other annotations were dropped: %#v", stored.Annotations)
+	}
+
+	restored := ConvertRecordFromV1(stored)
+	if !reflect.DeepEqual(restored.Spec.Records, set.Spec.Records) {
+		t.Errorf("records weren't restored from %s: %#v", RecordsAnnotation, restored.Spec.Records)
+	}
+	if _, ok := restored.Annotations[RecordsAnnotation]; ok {
+		t.Errorf("%s was left on the v2 record: %#v", RecordsAnnotation, restored.Annotations)
+	}
+
+	// a v1 client changing the record makes the kept records stale
+	changed := stored.DeepCopy()
+	changed.Spec.Value = "mx3.example.com."
+	restored = ConvertRecordFromV1(changed)
+	expected := []RecordData{{Host: "mx3.example.com.", Priority: 10}}
+	if !reflect.DeepEqual(restored.Spec.Records, expected) {
+		t.Errorf("stale %s was used: %#v", RecordsAnnotation, restored.Spec.Records)
+	}
+
+	// the annotation of a single record is dropped on the next conversion
+	single := ConvertRecordToV1(restored)
+	if _, ok := single.Annotations[RecordsAnnotation]; ok {
+		t.Errorf("%s kept for a single record: %#v", RecordsAnnotation, single.Annotations)
+	}
+
+	invalid := stored.DeepCopy()
+	invalid.Annotations[RecordsAnnotation] = "{"
+	restored = ConvertRecordFromV1(invalid)
+	expected = []RecordData{{Host: "mx1.example.com.", Priority: 10}}
+	if !reflect.DeepEqual(restored.Spec.Records, expected) {
+		t.Errorf("invalid %s wasn't ignored: %#v", RecordsAnnotation, restored.Spec.Records)
+	}
+	if _, ok := restored.Annotations[RecordsAnnotation]; ok {
+		t.Errorf("invalid %s was left on the v2 record: %#v", RecordsAnnotation, restored.Annotations)
+	}
+}
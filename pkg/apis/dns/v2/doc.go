@@ -0,0 +1,4 @@
+// +k8s:deepcopy-gen=package
+// +groupName=estaleiro.io
+
+package v2
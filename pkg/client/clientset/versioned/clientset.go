@@ -20,6 +20,7 @@ package versioned
 
 import (
 	estaleirov1 "github.com/estaleiro/dns-controller/pkg/client/clientset/versioned/typed/dns/v1"
+	estaleirov2 "github.com/estaleiro/dns-controller/pkg/client/clientset/versioned/typed/dns/v2"
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
 	flowcontrol "k8s.io/client-go/util/flowcontrol"
@@ -28,6 +29,7 @@ import (
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	EstaleiroV1() estaleirov1.EstaleiroV1Interface
+	EstaleiroV2() estaleirov2.EstaleiroV2Interface
 	// Deprecated: please explicitly pick a version if possible.
 	Estaleiro() estaleirov1.EstaleiroV1Interface
 }
@@ -37,6 +39,7 @@ type Interface interface {
 type Clientset struct {
 	*discovery.DiscoveryClient
 	estaleiroV1 *estaleirov1.EstaleiroV1Client
+	estaleiroV2 *estaleirov2.EstaleiroV2Client
 }
 
 // EstaleiroV1 retrieves the EstaleiroV1Client
@@ -44,6 +47,11 @@ func (c *Clientset) EstaleiroV1() estaleirov1.EstaleiroV1Interface {
 	return c.estaleiroV1
 }
 
+// EstaleiroV2 retrieves the EstaleiroV2Client
+func (c *Clientset) EstaleiroV2() estaleirov2.EstaleiroV2Interface {
+	return c.estaleiroV2
+}
+
 // Deprecated: Estaleiro retrieves the default version of EstaleiroClient.
 // Please explicitly pick a version.
 func (c *Clientset) Estaleiro() estaleirov1.EstaleiroV1Interface {
@@ -70,6 +78,10 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.estaleiroV2, err = estaleirov2.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfig(&configShallowCopy)
 	if err != nil {
@@ -83,6 +95,7 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 func NewForConfigOrDie(c *rest.Config) *Clientset {
 	var cs Clientset
 	cs.estaleiroV1 = estaleirov1.NewForConfigOrDie(c)
+	cs.estaleiroV2 = estaleirov2.NewForConfigOrDie(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClientForConfigOrDie(c)
 	return &cs
@@ -92,6 +105,7 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.estaleiroV1 = estaleirov1.New(c)
+	cs.estaleiroV2 = estaleirov2.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
@@ -22,6 +22,8 @@ import (
 	clientset "github.com/estaleiro/dns-controller/pkg/client/clientset/versioned"
 	estaleirov1 "github.com/estaleiro/dns-controller/pkg/client/clientset/versioned/typed/dns/v1"
 	fakeestaleirov1 "github.com/estaleiro/dns-controller/pkg/client/clientset/versioned/typed/dns/v1/fake"
+	estaleirov2 "github.com/estaleiro/dns-controller/pkg/client/clientset/versioned/typed/dns/v2"
+	fakeestaleirov2 "github.com/estaleiro/dns-controller/pkg/client/clientset/versioned/typed/dns/v2/fake"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
@@ -76,6 +78,11 @@ func (c *Clientset) EstaleiroV1() estaleirov1.EstaleiroV1Interface {
 	return &fakeestaleirov1.FakeEstaleiroV1{Fake: &c.Fake}
 }
 
+// EstaleiroV2 retrieves the EstaleiroV2Client
+func (c *Clientset) EstaleiroV2() estaleirov2.EstaleiroV2Interface {
+	return &fakeestaleirov2.FakeEstaleiroV2{Fake: &c.Fake}
+}
+
 // Estaleiro retrieves the EstaleiroV1Client
 func (c *Clientset) Estaleiro() estaleirov1.EstaleiroV1Interface {
 	return &fakeestaleirov1.FakeEstaleiroV1{Fake: &c.Fake}
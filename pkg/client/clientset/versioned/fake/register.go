@@ -20,6 +20,7 @@ package fake
 
 import (
 	estaleirov1 "github.com/estaleiro/dns-controller/pkg/apis/dns/v1"
+	estaleirov2 "github.com/estaleiro/dns-controller/pkg/apis/dns/v2"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -32,6 +33,7 @@ var codecs = serializer.NewCodecFactory(scheme)
 var parameterCodec = runtime.NewParameterCodec(scheme)
 var localSchemeBuilder = runtime.SchemeBuilder{
 	estaleirov1.AddToScheme,
+	estaleirov2.AddToScheme,
 }
 
 // AddToScheme adds all types of this clientset into the given scheme. This allows composition
@@ -39,6 +39,7 @@ type DNSRecordsGetter interface {
 type DNSRecordInterface interface {
 	Create(*v1.DNSRecord) (*v1.DNSRecord, error)
 	Update(*v1.DNSRecord) (*v1.DNSRecord, error)
+	UpdateStatus(*v1.DNSRecord) (*v1.DNSRecord, error)
 	Delete(name string, options *metav1.DeleteOptions) error
 	DeleteCollection(options *metav1.DeleteOptions, listOptions metav1.ListOptions) error
 	Get(name string, options metav1.GetOptions) (*v1.DNSRecord, error)
@@ -132,6 +133,22 @@ func (c *dNSRecords) Update(dNSRecord *v1.DNSRecord) (result *v1.DNSRecord, err
 	return
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *dNSRecords) UpdateStatus(dNSRecord *v1.DNSRecord) (result *v1.DNSRecord, err error) {
+	result = &v1.DNSRecord{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("dnsrecords").
+		Name(dNSRecord.Name).
+		SubResource("status").
+		Body(dNSRecord).
+		Do().
+		Into(result)
+	return
+}
+
 // Delete takes name of the dNSRecord and deletes it. Returns an error if one occurs.
 func (c *dNSRecords) Delete(name string, options *metav1.DeleteOptions) error {
 	return c.client.Delete().
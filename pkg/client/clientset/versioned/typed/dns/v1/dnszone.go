@@ -39,6 +39,7 @@ type DNSZonesGetter interface {
 type DNSZoneInterface interface {
 	Create(*v1.DNSZone) (*v1.DNSZone, error)
 	Update(*v1.DNSZone) (*v1.DNSZone, error)
+	UpdateStatus(*v1.DNSZone) (*v1.DNSZone, error)
 	Delete(name string, options *metav1.DeleteOptions) error
 	DeleteCollection(options *metav1.DeleteOptions, listOptions metav1.ListOptions) error
 	Get(name string, options metav1.GetOptions) (*v1.DNSZone, error)
@@ -132,6 +133,22 @@ func (c *dNSZones) Update(dNSZone *v1.DNSZone) (result *v1.DNSZone, err error) {
 	return
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *dNSZones) UpdateStatus(dNSZone *v1.DNSZone) (result *v1.DNSZone, err error) {
+	result = &v1.DNSZone{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("dnszones").
+		Name(dNSZone.Name).
+		SubResource("status").
+		Body(dNSZone).
+		Do().
+		Into(result)
+	return
+}
+
 // Delete takes name of the dNSZone and deletes it. Returns an error if one occurs.
 func (c *dNSZones) Delete(name string, options *metav1.DeleteOptions) error {
 	return c.client.Delete().
@@ -100,6 +100,18 @@ func (c *FakeDNSRecords) Update(dNSRecord *dnsv1.DNSRecord) (result *dnsv1.DNSRe
 	return obj.(*dnsv1.DNSRecord), err
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeDNSRecords) UpdateStatus(dNSRecord *dnsv1.DNSRecord) (*dnsv1.DNSRecord, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(dnsrecordsResource, "status", c.ns, dNSRecord), &dnsv1.DNSRecord{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*dnsv1.DNSRecord), err
+}
+
 // Delete takes name of the dNSRecord and deletes it. Returns an error if one occurs.
 func (c *FakeDNSRecords) Delete(name string, options *v1.DeleteOptions) error {
 	_, err := c.Fake.
@@ -100,6 +100,18 @@ func (c *FakeDNSZones) Update(dNSZone *dnsv1.DNSZone) (result *dnsv1.DNSZone, er
 	return obj.(*dnsv1.DNSZone), err
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeDNSZones) UpdateStatus(dNSZone *dnsv1.DNSZone) (*dnsv1.DNSZone, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(dnszonesResource, "status", c.ns, dNSZone), &dnsv1.DNSZone{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*dnsv1.DNSZone), err
+}
+
 // Delete takes name of the dNSZone and deletes it. Returns an error if one occurs.
 func (c *FakeDNSZones) Delete(name string, options *v1.DeleteOptions) error {
 	_, err := c.Fake.
@@ -22,6 +22,7 @@ import (
 	"fmt"
 
 	v1 "github.com/estaleiro/dns-controller/pkg/apis/dns/v1"
+	v2 "github.com/estaleiro/dns-controller/pkg/apis/dns/v2"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 )
@@ -58,6 +59,12 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case v1.SchemeGroupVersion.WithResource("dnszones"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Estaleiro().V1().DNSZones().Informer()}, nil
 
+		// Group=estaleiro.io, Version=v2
+	case v2.SchemeGroupVersion.WithResource("dnsrecords"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Estaleiro().V2().DNSRecords().Informer()}, nil
+	case v2.SchemeGroupVersion.WithResource("dnszones"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Estaleiro().V2().DNSZones().Informer()}, nil
+
 	}
 
 	return nil, fmt.Errorf("no informer found for %v", resource)
@@ -0,0 +1,46 @@
+package v1
+
+import (
+	"fmt"
+
+	v1 "github.com/estaleiro/dns-controller/pkg/apis/dns/v1"
+)
+
+// DNSRecordZoneIndex is the name of the index of DNSRecords by zone, the
+// informer feeding the lister must have it for ByZone to work
+const DNSRecordZoneIndex = "zoneName"
+
+// DNSRecordZoneIndexFunc indexes DNSRecords by their normalized spec.zoneName
+func DNSRecordZoneIndexFunc(obj interface{}) ([]string, error) {
+	record, ok := obj.(*v1.DNSRecord)
+	if !ok {
+		return nil, fmt.Errorf("expected DNSRecord but got %T", obj)
+	}
+	return []string{v1.NormalizeZoneName(record.Spec.ZoneName)}, nil
+}
+
+// DNSRecordListerExpansion allows custom methods to be added to
+// DNSRecordLister.
+type DNSRecordListerExpansion interface {
+	// ByZone lists the DNSRecords of a zone in all namespaces.
+	ByZone(zoneName string) ([]*v1.DNSRecord, error)
+}
+
+// DNSRecordNamespaceListerExpansion allows custom methods to be added to
+// DNSRecordNamespaceLister.
+type DNSRecordNamespaceListerExpansion interface{}
+
+// ByZone lists the DNSRecords of a zone in all namespaces, zoneName must be
+// normalized.
+func (s *dNSRecordLister) ByZone(zoneName string) ([]*v1.DNSRecord, error) {
+	objs, err := s.indexer.ByIndex(DNSRecordZoneIndex, zoneName)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*v1.DNSRecord, 0, len(objs))
+	for _, obj := range objs {
+		ret = append(ret, obj.(*v1.DNSRecord))
+	}
+	return ret, nil
+}
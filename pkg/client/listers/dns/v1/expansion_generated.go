@@ -18,14 +18,6 @@ limitations under the License.
 
 package v1
 
-// DNSRecordListerExpansion allows custom methods to be added to
-// DNSRecordLister.
-type DNSRecordListerExpansion interface{}
-
-// DNSRecordNamespaceListerExpansion allows custom methods to be added to
-// DNSRecordNamespaceLister.
-type DNSRecordNamespaceListerExpansion interface{}
-
 // DNSZoneListerExpansion allows custom methods to be added to
 // DNSZoneLister.
 type DNSZoneListerExpansion interface{}
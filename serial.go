@@ -0,0 +1,65 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/estaleiro/dns-controller/pkg/apis/dns"
+	v1 "github.com/estaleiro/dns-controller/pkg/apis/dns/v1"
+)
+
+// Annotations keeping the SOA serial of a zone across restarts
+var (
+	serialAnnotation      = dns.GroupName + "/serial"
+	contentHashAnnotation = dns.GroupName + "/content-hash"
+)
+
+// zoneSerial returns the serial and content hash last rendered for a zone
+func zoneSerial(zone *v1.DNSZone) (uint32, string) {
+	annotations := zone.GetAnnotations()
+
+	serial, err := strconv.ParseUint(annotations[serialAnnotation], 10, 32)
+	if err != nil {
+		return 0, ""
+	}
+
+	return uint32(serial), annotations[contentHashAnnotation]
+}
+
+// contentHash hashes a zone file rendered without its serial, so two
+// renders have the same hash only when their RRsets are the same
+func contentHash(file zoneFile) (string, error) {
+	file.Serial = 0
+
+	content, err := file.render()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// nextSerial returns the serial following previous for the strategy, it is
+// always greater than previous so secondaries pick up the change
+func nextSerial(strategy v1.SerialStrategy, previous uint32, now time.Time) uint32 {
+	var serial uint32
+
+	switch strategy {
+	case v1.SerialStrategyUnixTime:
+		serial = uint32(now.Unix())
+	case v1.SerialStrategyCounter:
+		serial = previous + 1
+	default:
+		now = now.UTC()
+		serial = uint32(now.Year())*1000000 + uint32(now.Month())*10000 + uint32(now.Day())*100
+	}
+
+	if serial <= previous {
+		serial = previous + 1
+	}
+
+	return serial
+}
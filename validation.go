@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	v1 "github.com/estaleiro/dns-controller/pkg/apis/dns/v1"
+)
+
+// maxTTL is the largest TTL and SOA timer allowed by RFC 2181
+const maxTTL = 2147483647
+
+// validateZone checks the name and SOA values of a DNSZone
+func validateZone(zone *v1.DNSZone) error {
+	spec := zone.Spec
+
+	if !isDomainName(zoneName(zone)) {
+		return fmt.Errorf("zone name %q is not a domain name", zoneName(zone))
+	}
+
+	timers := []struct {
+		field string
+		value int
+	}{
+		{"refresh", spec.Refresh},
+		{"retry", spec.Retry},
+		{"expire", spec.Expire},
+		{"minimum", spec.Minimum},
+		{"ttl", spec.TTL},
+	}
+	for _, timer := range timers {
+		if err := validateTTL(timer.field, timer.value); err != nil {
+			return err
+		}
+	}
+
+	switch spec.SerialStrategy {
+	case "", v1.SerialStrategyDate, v1.SerialStrategyUnixTime, v1.SerialStrategyCounter:
+	default:
+		return fmt.Errorf("unsupported serial strategy %q", spec.SerialStrategy)
+	}
+
+	if spec.PrimaryNS != "" && !isDomainName(spec.PrimaryNS) {
+		return fmt.Errorf("primaryNS %q is not a domain name", spec.PrimaryNS)
+	}
+
+	for _, nameServer := range spec.NameServers {
+		if !isDomainName(nameServer) {
+			return fmt.Errorf("nameServer %q is not a domain name", nameServer)
+		}
+	}
+
+	if spec.Hostmaster != "" && !isDomainName(strings.Replace(spec.Hostmaster, "@", ".", 1)) {
+		return fmt.Errorf("hostmaster %q is not an email address or domain name", spec.Hostmaster)
+	}
+
+	return nil
+}
+
+// validateRecordSet checks every record of the set a DNSRecord holds
+func validateRecordSet(zone string, record *v1.DNSRecord) error {
+	set := recordSet(record)
+
+	if len(set) == 0 {
+		return fmt.Errorf("record set has no records")
+	}
+
+	if record.Spec.Type == v1.RecordTypeCNAME && len(set) > 1 {
+		return fmt.Errorf("a CNAME record set holds a single record")
+	}
+
+	for _, entry := range set {
+		if err := validateRecord(zone, entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateRecord checks a DNSRecord can be rendered into the zone
+func validateRecord(zone string, record *v1.DNSRecord) error {
+	spec := record.Spec
+
+	if err := validateOwner(zone, spec.Name); err != nil {
+		return err
+	}
+
+	if err := validateTTL("ttl", spec.TTL); err != nil {
+		return err
+	}
+
+	switch spec.Type {
+	case v1.RecordTypeA:
+		// IPv4-mapped IPv6 addresses parse as IPv4 too, the colons tell them
+		// apart
+		if ip := net.ParseIP(spec.Value); ip == nil || strings.Contains(spec.Value, ":") {
+			return fmt.Errorf("value %q is not an IPv4 address", spec.Value)
+		}
+	case v1.RecordTypeAAAA:
+		if ip := net.ParseIP(spec.Value); ip == nil || !strings.Contains(spec.Value, ":") {
+			return fmt.Errorf("value %q is not an IPv6 address", spec.Value)
+		}
+	case v1.RecordTypeCNAME, v1.RecordTypeNS, v1.RecordTypePTR:
+		if !isDomainName(spec.Value) {
+			return fmt.Errorf("value %q is not a domain name", spec.Value)
+		}
+		// the apex always has SOA and NS records
+		if spec.Type == v1.RecordTypeCNAME && absoluteOwner(zone, spec.Name) == zone {
+			return fmt.Errorf("CNAME is not allowed at the zone apex")
+		}
+	case v1.RecordTypeMX:
+		if !isDomainName(spec.Value) {
+			return fmt.Errorf("value %q is not a domain name", spec.Value)
+		}
+		if err := validateUint16("priority", spec.Priority); err != nil {
+			return err
+		}
+	case v1.RecordTypeSRV:
+		if !isDomainName(spec.Value) {
+			return fmt.Errorf("value %q is not a domain name", spec.Value)
+		}
+		if err := validateUint16("priority", spec.Priority); err != nil {
+			return err
+		}
+		if err := validateUint16("weight", spec.Weight); err != nil {
+			return err
+		}
+		if err := validateUint16("port", spec.Port); err != nil {
+			return err
+		}
+	case v1.RecordTypeTXT:
+	default:
+		return fmt.Errorf("unsupported record type %q", spec.Type)
+	}
+
+	return nil
+}
+
+// validateNamespace checks records in namespace may use the zone
+func validateNamespace(zone *v1.DNSZone, namespace string) error {
+	allowed := zone.Spec.AllowedNamespaces
+	if len(allowed) == 0 || namespace == zone.GetNamespace() {
+		return nil
+	}
+
+	for _, allowedNamespace := range allowed {
+		if allowedNamespace == namespace {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("namespace %s is not allowed to use zone %s", namespace, zoneName(zone))
+}
+
+// validateOwner checks the record name is a valid owner name inside the zone
+func validateOwner(zone, name string) error {
+	if name == "" || name == "@" {
+		return nil
+	}
+
+	zone = strings.TrimSuffix(zone, ".")
+	owner := strings.ToLower(strings.TrimSuffix(name, "."))
+	if strings.HasPrefix(owner, "*.") {
+		owner = strings.TrimPrefix(owner, "*.")
+	} else if owner == "*" {
+		return nil
+	}
+
+	if !isDomainName(owner) {
+		return fmt.Errorf("name %q is not a domain name", name)
+	}
+
+	if strings.HasSuffix(name, ".") {
+		if owner != zone && !strings.HasSuffix(owner, "."+zone) {
+			return fmt.Errorf("name %q is outside of zone %s", name, zone)
+		}
+	}
+
+	return nil
+}
+
+// isDomainName checks name is made of valid labels, a trailing dot is allowed
+func isDomainName(name string) bool {
+	name = strings.TrimSuffix(name, ".")
+	if name == "" || len(name) > 253 {
+		return false
+	}
+
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > 63 {
+			return false
+		}
+		for _, c := range label {
+			switch {
+			case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+			case c == '-' || c == '_':
+			default:
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// validateTTL checks a TTL or SOA timer fits in 31 bits
+func validateTTL(field string, value int) error {
+	if value < 0 || value > maxTTL {
+		return fmt.Errorf("%s %d must be between 0 and %d", field, value, maxTTL)
+	}
+	return nil
+}
+
+// validateUint16 checks value fits the 16 bit fields of MX and SRV records
+func validateUint16(field string, value int) error {
+	if value < 0 || value > 65535 {
+		return fmt.Errorf("%s %d must be between 0 and 65535", field, value)
+	}
+	return nil
+}
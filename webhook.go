@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	v1 "github.com/estaleiro/dns-controller/pkg/apis/dns/v1"
+	v2 "github.com/estaleiro/dns-controller/pkg/apis/dns/v2"
+	listers "github.com/estaleiro/dns-controller/pkg/client/listers/dns/v1"
+	log "github.com/sirupsen/logrus"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// admissionWebhook rejects zones and records the controller could not
+// serve, objects of other controller classes are left to their instance
+type admissionWebhook struct {
+	zoneLister   listers.DNSZoneLister
+	recordLister listers.DNSRecordLister
+	class        string
+	hasSynced    func() bool
+}
+
+// serveWebhook serves the admission webhook on /validate and the conversion
+// webhook on /convert over HTTPS until the process exits
+func serveWebhook(address, certFile, keyFile string, webhook *admissionWebhook) {
+	mux := http.NewServeMux()
+	mux.Handle("/validate", webhook)
+	mux.HandleFunc("/convert", conversionHandler)
+
+	log.Infof("serving admission and conversion webhooks on %s", address)
+	if err := http.ListenAndServeTLS(address, certFile, keyFile, mux); err != nil {
+		log.Fatalf("error serving admission webhook: %v", err)
+	}
+}
+
+// ServeHTTP answers an AdmissionReview
+func (w *admissionWebhook) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(rw, fmt.Sprintf("error reading request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	review := admissionv1beta1.AdmissionReview{}
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(rw, "expected an AdmissionReview request", http.StatusBadRequest)
+		return
+	}
+
+	response := &admissionv1beta1.AdmissionResponse{UID: review.Request.UID, Allowed: true}
+	if err := w.admit(review.Request); err != nil {
+		log.WithFields(log.Fields{
+			"resource":  review.Request.Kind.Kind,
+			"namespace": review.Request.Namespace,
+			"name":      review.Request.Name,
+		}).Debugf("admission denied: %v", err)
+		response.Allowed = false
+		response.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  metav1.StatusReasonInvalid,
+			Message: err.Error(),
+			Code:    http.StatusUnprocessableEntity,
+		}
+	}
+
+	review.Request = nil
+	review.Response = response
+
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(review); err != nil {
+		log.Errorf("error writing admission response: %v", err)
+	}
+}
+
+// admit validates the object of a create or update request. Updates that
+// leave the spec alone or hit an object being deleted are allowed, the
+// controller must always be able to change finalizers and annotations
+func (w *admissionWebhook) admit(request *admissionv1beta1.AdmissionRequest) error {
+	if request.Operation != admissionv1beta1.Create && request.Operation != admissionv1beta1.Update {
+		return nil
+	}
+
+	switch request.Kind.Kind {
+	case "DNSZone":
+		zone := &v1.DNSZone{}
+		if err := json.Unmarshal(request.Object.Raw, zone); err != nil {
+			return fmt.Errorf("error decoding DNSZone: %v", err)
+		}
+		if request.Operation == admissionv1beta1.Update {
+			old := &v1.DNSZone{}
+			if err := json.Unmarshal(request.OldObject.Raw, old); err != nil {
+				return fmt.Errorf("error decoding DNSZone: %v", err)
+			}
+			if zone.GetDeletionTimestamp() != nil || equality.Semantic.DeepEqual(old.Spec, zone.Spec) {
+				return nil
+			}
+		}
+		return w.validateZone(zone)
+	case "DNSRecord":
+		record := &v1.DNSRecord{}
+		if err := json.Unmarshal(request.Object.Raw, record); err != nil {
+			return fmt.Errorf("error decoding DNSRecord: %v", err)
+		}
+		if request.Operation == admissionv1beta1.Update {
+			old := &v1.DNSRecord{}
+			if err := json.Unmarshal(request.OldObject.Raw, old); err != nil {
+				return fmt.Errorf("error decoding DNSRecord: %v", err)
+			}
+			// record sets written through v2 live in an annotation too
+			if record.GetDeletionTimestamp() != nil || (equality.Semantic.DeepEqual(old.Spec, record.Spec) &&
+				old.GetAnnotations()[v2.RecordsAnnotation] == record.GetAnnotations()[v2.RecordsAnnotation]) {
+				return nil
+			}
+		}
+		// objects may be created without the namespace of the request
+		if record.GetNamespace() == "" {
+			record.SetNamespace(request.Namespace)
+		}
+		return w.validateRecord(record)
+	}
+
+	return nil
+}
+
+// validateZone checks the zone can be rendered
+func (w *admissionWebhook) validateZone(zone *v1.DNSZone) error {
+	if zone.Spec.ControllerClass != w.class {
+		return nil
+	}
+
+	return validateZone(zone)
+}
+
+// validateRecord checks the record is valid, inside its zone, allowed to
+// use the zone and doesn't conflict with the records already in the zone
+func (w *admissionWebhook) validateRecord(record *v1.DNSRecord) error {
+	if record.Spec.ControllerClass != w.class {
+		return nil
+	}
+
+	if err := validateRecordSet(recordZoneName(record), record); err != nil {
+		return err
+	}
+
+	// conflicts can only be told with complete caches
+	if !w.hasSynced() {
+		return fmt.Errorf("dns-controller caches are not synced yet, try again")
+	}
+
+	zones, err := w.zoneLister.List(labels.Everything())
+	if err != nil {
+		return fmt.Errorf("error listing zones: %v", err)
+	}
+
+	// the record is checked again once its zone is created
+	zone := activeZone(zones, recordZoneName(record))
+	if zone == nil {
+		return nil
+	}
+
+	if err := validateNamespace(zone, record.GetNamespace()); err != nil {
+		return err
+	}
+
+	records, err := w.recordLister.ByZone(recordZoneName(record))
+	if err != nil {
+		return fmt.Errorf("error listing records: %v", err)
+	}
+
+	origin := zoneName(zone)
+	owners := map[string]ownerData{origin: {other: true}}
+	for _, other := range records {
+		// the record being updated is replaced, records the zone file
+		// leaves out hold no data
+		if other.GetNamespace() == record.GetNamespace() && other.GetName() == record.GetName() {
+			continue
+		}
+		if validateRecordSet(recordZoneName(record), other) != nil || validateNamespace(zone, other.GetNamespace()) != nil {
+			continue
+		}
+
+		owner := absoluteOwner(origin, other.Spec.Name)
+		data := owners[owner]
+		if data.add(other.Spec.Type) == nil {
+			owners[owner] = data
+		}
+	}
+
+	owner := absoluteOwner(origin, record.Spec.Name)
+	data := owners[owner]
+	if err := data.add(record.Spec.Type); err != nil {
+		return fmt.Errorf("%s: %v", owner, err)
+	}
+
+	return nil
+}
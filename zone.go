@@ -0,0 +1,63 @@
+package main
+
+import (
+	v1 "github.com/estaleiro/dns-controller/pkg/apis/dns/v1"
+)
+
+// zoneName returns the DNS name served by a DNSZone as a lowercase FQDN,
+// spec.zoneName or the object name when it is empty
+func zoneName(zone *v1.DNSZone) string {
+	if zone.Spec.ZoneName != "" {
+		return v1.NormalizeZoneName(zone.Spec.ZoneName)
+	}
+	return v1.NormalizeZoneName(zone.GetObjectMeta().GetName())
+}
+
+// recordZoneName returns the DNS name of the zone a DNSRecord belongs to as
+// a lowercase FQDN
+func recordZoneName(record *v1.DNSRecord) string {
+	return v1.NormalizeZoneName(record.Spec.ZoneName)
+}
+
+// activeZone returns the DNSZone serving name, or nil if there is none.
+// When the same zone is declared in several namespaces the oldest object
+// wins, zones being deleted are ignored
+func activeZone(zones []*v1.DNSZone, name string) *v1.DNSZone {
+	var active *v1.DNSZone
+
+	for _, zone := range zones {
+		if zoneName(zone) != name || zone.GetDeletionTimestamp() != nil {
+			continue
+		}
+
+		if active == nil || olderZone(zone, active) {
+			active = zone
+		}
+	}
+
+	return active
+}
+
+// olderZone reports if a was created before b, ties are broken by namespace
+// so the pick doesn't depend on the order zones are listed in
+func olderZone(a, b *v1.DNSZone) bool {
+	aCreated, bCreated := a.GetCreationTimestamp(), b.GetCreationTimestamp()
+	if !aCreated.Equal(&bCreated) {
+		return aCreated.Before(&bCreated)
+	}
+	return a.GetNamespace() < b.GetNamespace()
+}
+
+// zoneRecords returns the records referencing the zone name, records being
+// deleted are left out
+func zoneRecords(records []*v1.DNSRecord, name string) []*v1.DNSRecord {
+	var found []*v1.DNSRecord
+
+	for _, record := range records {
+		if recordZoneName(record) == name && record.GetDeletionTimestamp() == nil {
+			found = append(found, record)
+		}
+	}
+
+	return found
+}
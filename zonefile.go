@@ -0,0 +1,371 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	v1 "github.com/estaleiro/dns-controller/pkg/apis/dns/v1"
+	v2 "github.com/estaleiro/dns-controller/pkg/apis/dns/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+// fileMarker is on the first line of every file the controller writes, it
+// must match zone.tmpl and coredns.tmpl
+const fileMarker = "generated by dns-controller"
+
+// maxTXTString is the longest character-string allowed in a TXT record
+const maxTXTString = 255
+
+// SOA values used when the DNSZone leaves them unset
+const (
+	defaultRefresh = 3600
+	defaultRetry   = 600
+	defaultExpire  = 604800
+	defaultMinimum = 3600
+	defaultTTL     = 3600
+)
+
+// zoneFile holds the data used to execute zone.tmpl
+type zoneFile struct {
+	Origin     string
+	TTL        int
+	PrimaryNS  string
+	Hostmaster string
+	Serial     uint32
+	Refresh    int
+	Retry      int
+	Expire     int
+	Minimum    int
+	Records    []zoneRecord
+
+	// path is where the master file is written
+	path string
+	// rendered counts the DNSRecords written to the file
+	rendered int
+	// skipped holds why records were left out, by namespace/name
+	skipped map[string]skippedRecord
+}
+
+// skippedRecord explains why a DNSRecord was left out of the zone file
+type skippedRecord struct {
+	condition v1.ConditionType
+	reason    string
+	err       error
+}
+
+// zoneRecord is a resource record line of the zone file
+type zoneRecord struct {
+	Owner string
+	TTL   string
+	Type  v1.RecordType
+	Data  string
+}
+
+// newZoneFile builds the zone file content of a zone, records that
+// can't be rendered are skipped
+func newZoneFile(logger *log.Entry, zone *v1.DNSZone, records []*v1.DNSRecord) zoneFile {
+	name := zoneName(zone)
+	spec := zone.Spec
+
+	file := zoneFile{
+		Origin:     name,
+		TTL:        valueOrDefault(spec.TTL, defaultTTL),
+		PrimaryNS:  fqdn(spec.PrimaryNS),
+		Hostmaster: hostmasterMailbox(spec.Hostmaster),
+		Refresh:    valueOrDefault(spec.Refresh, defaultRefresh),
+		Retry:      valueOrDefault(spec.Retry, defaultRetry),
+		Expire:     valueOrDefault(spec.Expire, defaultExpire),
+		Minimum:    valueOrDefault(spec.Minimum, defaultMinimum),
+	}
+	if spec.PrimaryNS == "" {
+		file.PrimaryNS = "ns1." + file.Origin
+	}
+	if file.Hostmaster == "" {
+		file.Hostmaster = "hostmaster." + file.Origin
+	}
+
+	file.skipped = map[string]skippedRecord{}
+	seen := map[zoneRecord]bool{}
+
+	// a CNAME can't share its name with other data, the zone apex always
+	// has SOA and NS records
+	owners := map[string]ownerData{file.Origin: {other: true}}
+
+	nameServers := spec.NameServers
+	if len(nameServers) == 0 {
+		nameServers = []string{file.PrimaryNS}
+	}
+	for _, nameServer := range nameServers {
+		line := zoneRecord{Owner: "@", Type: v1.RecordTypeNS, Data: fqdn(nameServer)}
+		seen[line] = true
+		file.Records = append(file.Records, line)
+	}
+
+	// older records take precedence when records conflict
+	records = append([]*v1.DNSRecord(nil), records...)
+	sort.Slice(records, func(i, j int) bool {
+		return olderRecord(records[i], records[j])
+	})
+
+	for _, record := range records {
+		key := record.GetNamespace() + "/" + record.GetName()
+
+		if err := validateRecordSet(name, record); err != nil {
+			objectLogger(logger, Record, record).Debugf("skipping invalid record: %v", err)
+			file.skipped[key] = skippedRecord{condition: v1.ConditionInvalid, reason: reasonInvalidSpec, err: err}
+			continue
+		}
+
+		if err := validateNamespace(zone, record.GetNamespace()); err != nil {
+			objectLogger(logger, Record, record).Debugf("skipping record from another namespace: %v", err)
+			file.skipped[key] = skippedRecord{condition: v1.ConditionInvalid, reason: reasonNamespaceNotAllowed, err: err}
+			continue
+		}
+
+		owner := absoluteOwner(file.Origin, record.Spec.Name)
+		data := owners[owner]
+		if err := data.add(record.Spec.Type); err != nil {
+			objectLogger(logger, Record, record).Debugf("skipping conflicting record: %v", err)
+			file.skipped[key] = skippedRecord{condition: v1.ConditionConflict, reason: reasonNameConflict, err: err}
+			continue
+		}
+		owners[owner] = data
+
+		file.rendered++
+		for _, entry := range recordSet(record) {
+			line := zoneRecord{
+				Owner: recordOwner(entry),
+				Type:  entry.Spec.Type,
+				Data:  recordData(entry),
+			}
+			if entry.Spec.TTL > 0 {
+				line.TTL = strconv.Itoa(entry.Spec.TTL)
+			}
+
+			if seen[line] {
+				continue
+			}
+			seen[line] = true
+			file.Records = append(file.Records, line)
+		}
+	}
+
+	// keep the output stable no matter the order records are listed
+	sort.Slice(file.Records, func(i, j int) bool {
+		a, b := file.Records[i], file.Records[j]
+		if a.Owner != b.Owner {
+			return a.Owner < b.Owner
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Data < b.Data
+	})
+
+	return file
+}
+
+// zone.tmpl is parsed on the first render and reused afterwards
+var (
+	zoneTemplateOnce sync.Once
+	zoneTemplate     *template.Template
+	zoneTemplateErr  error
+)
+
+// render executes zone.tmpl
+func (z zoneFile) render() ([]byte, error) {
+	zoneTemplateOnce.Do(func() {
+		zoneTemplate, zoneTemplateErr = template.ParseFiles("zone.tmpl")
+	})
+	if zoneTemplateErr != nil {
+		return nil, zoneTemplateErr
+	}
+
+	var buf bytes.Buffer
+	if err := zoneTemplate.Execute(&buf, z); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ownerData tracks the kind of data a name already holds
+type ownerData struct {
+	cname bool
+	other bool
+}
+
+// add records a new type at the name, failing if it conflicts with a CNAME
+func (o *ownerData) add(recordType v1.RecordType) error {
+	if o.cname {
+		return fmt.Errorf("name already has a CNAME record")
+	}
+	if recordType == v1.RecordTypeCNAME {
+		if o.other {
+			return fmt.Errorf("CNAME can't coexist with other data at the same name")
+		}
+		o.cname = true
+		return nil
+	}
+	o.other = true
+	return nil
+}
+
+// absoluteOwner returns the lowercase absolute owner name of a record name
+func absoluteOwner(origin, name string) string {
+	switch {
+	case name == "" || name == "@":
+		name = origin
+	case !strings.HasSuffix(name, "."):
+		name = name + "." + origin
+	}
+	return strings.ToLower(name)
+}
+
+// olderRecord reports if a was created before b, ties are broken by key
+func olderRecord(a, b *v1.DNSRecord) bool {
+	aCreated, bCreated := a.GetCreationTimestamp(), b.GetCreationTimestamp()
+	if !aCreated.Equal(&bCreated) {
+		return aCreated.Before(&bCreated)
+	}
+	if a.GetNamespace() != b.GetNamespace() {
+		return a.GetNamespace() < b.GetNamespace()
+	}
+	return a.GetName() < b.GetName()
+}
+
+// ownedFile reports if the file was written by the controller
+func ownedFile(name string) bool {
+	file, err := os.Open(name)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	line, err := bufio.NewReader(file).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false
+	}
+
+	return strings.Contains(line, fileMarker)
+}
+
+// valueOrDefault returns value, or def when value is unset
+func valueOrDefault(value, def int) int {
+	if value == 0 {
+		return def
+	}
+	return value
+}
+
+// fqdn returns name with a trailing dot, host names are always absolute so
+// they aren't completed with the zone origin
+func fqdn(name string) string {
+	if name == "" || strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+// hostmasterMailbox converts an email address to the mailbox domain name
+// used in the SOA record, the dots of the local part are escaped
+func hostmasterMailbox(hostmaster string) string {
+	at := strings.LastIndex(hostmaster, "@")
+	if at < 0 {
+		return fqdn(hostmaster)
+	}
+
+	local := strings.Replace(hostmaster[:at], ".", `\.`, -1)
+	return local + "." + strings.TrimSuffix(hostmaster[at+1:], ".") + "."
+}
+
+// recordOwner returns the owner name of a record as written in the zone file
+func recordOwner(record *v1.DNSRecord) string {
+	if record.Spec.Name == "" {
+		return "@"
+	}
+	return record.Spec.Name
+}
+
+// recordSet returns a copy of record per record of the set it holds, sets
+// written through the v2 API keep all but the first in an annotation
+func recordSet(record *v1.DNSRecord) []*v1.DNSRecord {
+	recordSet := v2.ConvertRecordFromV1(record)
+
+	var set []*v1.DNSRecord
+	for _, data := range recordSet.Spec.Records {
+		recordSet.Spec.Records = []v2.RecordData{data}
+		set = append(set, v2.ConvertRecordToV1(recordSet))
+	}
+	return set
+}
+
+// recordData returns the RDATA of a record in presentation format
+func recordData(record *v1.DNSRecord) string {
+	spec := record.Spec
+
+	switch spec.Type {
+	case v1.RecordTypeMX:
+		return fmt.Sprintf("%d %s", spec.Priority, fqdn(spec.Value))
+	case v1.RecordTypeSRV:
+		return fmt.Sprintf("%d %d %d %s", spec.Priority, spec.Weight, spec.Port, fqdn(spec.Value))
+	case v1.RecordTypeCNAME, v1.RecordTypeNS, v1.RecordTypePTR:
+		return fqdn(spec.Value)
+	case v1.RecordTypeTXT:
+		return quoteTXT(spec.Value)
+	default:
+		return spec.Value
+	}
+}
+
+// quoteTXT quotes text as TXT character-strings, splitting it when it's
+// longer than a single string can hold
+func quoteTXT(text string) string {
+	var chunks []string
+
+	for {
+		chunk := text
+		if len(chunk) > maxTXTString {
+			chunk = chunk[:maxTXTString]
+		}
+		text = text[len(chunk):]
+
+		chunks = append(chunks, `"`+escapeTXT(chunk)+`"`)
+
+		if text == "" {
+			break
+		}
+	}
+
+	return strings.Join(chunks, " ")
+}
+
+// escapeTXT escapes a character-string for the master file, backslashes and
+// quotes are escaped with a backslash, control and non-ASCII bytes as \DDD
+// (RFC 1035 section 5.1)
+func escapeTXT(text string) string {
+	var buf strings.Builder
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\\' || c == '"':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case c < ' ' || c > '~':
+			fmt.Fprintf(&buf, "\\%03d", c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+
+	return buf.String()
+}